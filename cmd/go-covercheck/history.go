@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/mach6/go-covercheck/pkg/compute"
 	"github.com/mach6/go-covercheck/pkg/config"
@@ -15,21 +16,16 @@ import (
 	"golang.org/x/term"
 )
 
-func handleHistoryOperations(cmd *cobra.Command, results compute.Results, cfg *config.Config) error {
+// handleHistoryOperations saves results to history, when requested, along
+// with the line detail of the files when --history-lines is set.
+func handleHistoryOperations(cmd *cobra.Command, results compute.Results, files []history.FileLines,
+	cfg *config.Config) error {
 	historyLimit, _ := cmd.Flags().GetInt(HistoryLimitFlag)
 
-	// compare results against history, when requested
-	compareRef, _ := cmd.Flags().GetString(CompareHistoryFlag)
-	if compareRef != "" {
-		if err := compareHistory(cmd, compareRef, results); err != nil {
-			return err
-		}
-	}
-
 	// save results to history, when requested.
 	bSaveHistory, _ := cmd.Flags().GetBool(SaveHistoryFlag)
 	if bSaveHistory {
-		if err := saveHistory(cmd, results, historyLimit, cfg); err != nil {
+		if err := saveHistory(cmd, results, files, historyLimit, cfg); err != nil {
 			return err
 		}
 	}
@@ -38,12 +34,29 @@ func handleHistoryOperations(cmd *cobra.Command, results compute.Results, cfg *c
 }
 
 func getHistory(cmd *cobra.Command) (*history.History, error) {
-	historyFile, err := getHistoryPath(cmd)
+	s, err := getHistoryStorage(cmd)
 	if err != nil {
 		return nil, err
 	}
 	// loads previous history if it exists
-	return history.Load(historyFile)
+	return history.LoadStorage(s)
+}
+
+// getHistoryStorage returns the history.Storage of the --history-file URI.
+func getHistoryStorage(cmd *cobra.Command) (history.Storage, error) {
+	historyFile, err := getHistoryPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return history.OpenStorage(historyFile)
+}
+
+// getHistoryFile returns the path of the history file when history is stored
+// in a plain file rather than in a directory or git.
+func getHistoryFile(cmd *cobra.Command) (string, bool) {
+	historyFile, _ := cmd.Flags().GetString(HistoryFileFlag)
+	scheme, location := history.ParseURI(historyFile)
+	return location, scheme == history.SchemeFile && location != ""
 }
 
 func getHistoryPath(cmd *cobra.Command) (string, error) {
@@ -54,26 +67,46 @@ func getHistoryPath(cmd *cobra.Command) (string, error) {
 	return historyFile, nil
 }
 
-func saveHistory(cmd *cobra.Command, results compute.Results, historyLimit int, cfg *config.Config) error {
-	h, err := getHistory(cmd)
+func saveHistory(cmd *cobra.Command, results compute.Results, files []history.FileLines, historyLimit int,
+	cfg *config.Config) error {
+	s, err := getHistoryStorage(cmd)
+	if err != nil {
+		return err
+	}
+	h, err := history.LoadStorage(s)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			path, _ := cmd.Flags().GetString(HistoryFileFlag)
-			h = history.New(path)
+			h = history.NewWithStorage(s)
 		} else {
 			return fmt.Errorf("failed to load history: %w", err)
 		}
 	}
 
+	key, _ := cmd.Flags().GetString(HistoryKeyFlag)
+	if err := h.SetKey(key); err != nil {
+		return err
+	}
+	pairs, _ := cmd.Flags().GetStringArray(BuildMetadataFlag)
+	metadata, err := history.ParsePairs(pairs)
+	if err != nil {
+		return fmt.Errorf("--%s: %w", BuildMetadataFlag, err)
+	}
+	h.SetMetadata(metadata)
+	h.SetFiles(files)
 	label, _ := cmd.Flags().GetString(HistoryLabelFlag)
 	h.AddResults(results, label)
+	if cfg.Retention.IsSet() {
+		if _, err := h.Prune(cfg.Retention, time.Now()); err != nil {
+			return err
+		}
+	}
 
 	if err := h.Save(historyLimit); err != nil {
 		return err
 	}
 
 	// Only show success messages for non-JSON/YAML formats
-	if cfg.Format != config.FormatJSON && cfg.Format != config.FormatYAML {
+	if !cfg.IsDocumentFormat() {
 		if label != "" {
 			fmt.Printf("≡ Saved history entry with label: %s\n", label)
 		} else {
@@ -83,27 +116,251 @@ func saveHistory(cmd *cobra.Command, results compute.Results, historyLimit int,
 	return nil
 }
 
-func compareHistory(cmd *cobra.Command, compareRef string, results compute.Results) error {
-	h, err := getHistory(cmd)
+// compareHistory compares results against the history entry of the
+// --compare-history ref or of the --compare-merge-base target, against the
+// best historical values with --compare-best or, when regression gating is
+// enabled without any of them, the latest entry on the regression baseline
+// branch. The line detail of the files is compared when the entry recorded it.
+// It returns nil when no comparison is requested.
+func compareHistory(cmd *cobra.Command, results compute.Results, files func() []history.FileLines,
+	cfg *config.Config) (*history.Comparison, error) {
+	compareRef, _ := cmd.Flags().GetString(CompareHistoryFlag)
+	mergeBase, _ := cmd.Flags().GetString(CompareMergeBaseFlag)
+	best, _ := cmd.Flags().GetBool(CompareBestFlag)
+	if compareRef != "" && mergeBase != "" {
+		return nil, fmt.Errorf("--%s cannot be combined with --%s", CompareMergeBaseFlag, CompareHistoryFlag)
+	}
+	if best && (compareRef != "" || mergeBase != "") {
+		return nil, fmt.Errorf("--%s cannot be combined with --%s or --%s",
+			CompareBestFlag, CompareHistoryFlag, CompareMergeBaseFlag)
+	}
+	baseline := ""
+	if compareRef == "" && mergeBase == "" && !best && cfg.Regression.Fail {
+		baseline = cfg.Regression.Baseline
+		if baseline == "" {
+			return nil, fmt.Errorf("--%s requires --%s, --%s, --%s, or --%s", FailOnRegressionFlag,
+				CompareHistoryFlag, CompareMergeBaseFlag, CompareBestFlag, RegressionBaselineFlag)
+		}
+	}
+	if compareRef == "" && mergeBase == "" && !best && baseline == "" {
+		return nil, nil //nolint:nilnil // no comparison requested
+	}
+
+	h, err := queryHistory(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to load history: %w", err)
+		return nil, err
 	}
 
-	refEntry := h.FindByRef(compareRef)
-	if refEntry == nil {
-		return fmt.Errorf("no history entry found for ref: %s", compareRef)
+	if best {
+		if err := scopeBest(cmd, h); err != nil {
+			return nil, err
+		}
+		c := h.CompareBest(results)
+		if c == nil {
+			return nil, errors.New("no history entries found to compare the best of")
+		}
+		return c, nil
+	}
+
+	ref := compareRef
+	var refEntry *history.Entry
+	switch {
+	case mergeBase != "":
+		ref = mergeBase
+		if refEntry, err = h.FindByMergeBase(mergeBase); err != nil {
+			return nil, err
+		}
+		if refEntry == nil {
+			return nil, fmt.Errorf("no history entry found for the merge-base of HEAD and %s "+
+				"or its first-parent ancestors", mergeBase)
+		}
+	case baseline != "":
+		ref = baseline
+		if refEntry = h.LatestOnBranch(baseline); refEntry == nil {
+			return nil, fmt.Errorf("no history entry found for regression baseline branch: %s", baseline)
+		}
+	default:
+		if refEntry, err = h.FindByRef(compareRef); err != nil {
+			return nil, err
+		}
+		if refEntry == nil {
+			return nil, fmt.Errorf("no history entry found for ref: %s", compareRef)
+		}
 	}
-	output.CompareHistory(compareRef, refEntry, results)
+
+	c := history.Compare(ref, refEntry, results)
+	if len(refEntry.Files) > 0 {
+		c.ApplyLines(refEntry, files())
+	}
+	return c, nil
+}
+
+// scopeBest keeps the history entries of the --best-scope, being those of the
+// --label recorded on the current branch by default. The --filter-branch
+// branches, when given, replace the current branch.
+func scopeBest(cmd *cobra.Command, h *history.History) error {
+	scope, _ := cmd.Flags().GetString(BestScopeFlag)
+	label, _ := cmd.Flags().GetString(HistoryLabelFlag)
+	branch := ""
+	if branches, _ := cmd.Flags().GetStringSlice(FilterBranchFlag); len(branches) == 0 {
+		branch = history.DetectGitInfo(".").Branch
+	}
+	if err := h.ScopeBest(scope, label, branch); err != nil {
+		return fmt.Errorf("--%s: %w", BestScopeFlag, err)
+	}
+	return nil
+}
+
+// diffHistory compares the history entries of two refs given as from,to.
+func diffHistory(cmd *cobra.Command, refs []string, cfg *config.Config) error {
+	if len(refs) != 2 { //nolint:mnd // from and to
+		return fmt.Errorf("--%s requires two refs given as from,to", DiffHistoryFlag)
+	}
+	if cfg.Format == config.FormatDashboard {
+		return fmt.Errorf("--%s does not support the %s format", DiffHistoryFlag, config.FormatDashboard)
+	}
+
+	h, err := queryHistory(cmd)
+	if err != nil {
+		return err
+	}
+
+	fromRef, toRef := refs[0], refs[1]
+	from, err := h.FindByRef(fromRef)
+	if err != nil {
+		return err
+	}
+	if from == nil {
+		return fmt.Errorf("no history entry found for ref: %s", fromRef)
+	}
+	to, err := h.FindByRef(toRef)
+	if err != nil {
+		return err
+	}
+	if to == nil {
+		return fmt.Errorf("no history entry found for ref: %s", toRef)
+	}
+
+	output.ReportComparison(history.CompareEntries(fromRef, from, toRef, to), cfg)
 	return nil
 }
 
+// queryHistory loads the history and keeps the entries matching the history
+// filter flags, such as --filter-branch and --since. The result is only read,
+// never saved.
+func queryHistory(cmd *cobra.Command) (*history.History, error) {
+	h, err := getHistory(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	pairs, _ := cmd.Flags().GetStringArray(FilterBuildFlag)
+	build, err := history.ParsePairs(pairs)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", FilterBuildFlag, err)
+	}
+	q := history.Query{Build: build}
+	q.Branches, _ = cmd.Flags().GetStringSlice(FilterBranchFlag)
+	q.Label, _ = cmd.Flags().GetString(FilterLabelFlag)
+	q.Tagged, _ = cmd.Flags().GetBool(FilterTaggedFlag)
+	q.Commits, _ = cmd.Flags().GetString(FilterCommitsFlag)
+	q.Since, _ = cmd.Flags().GetString(SinceFlag)
+	q.Until, _ = cmd.Flags().GetString(UntilFlag)
+	if err := h.Select(q, time.Now()); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
 func showHistory(cmd *cobra.Command, historyLimit int, cfg *config.Config) error {
+	h, err := queryHistory(cmd)
+	if err != nil {
+		return err
+	}
+
+	output.ShowHistory(h, historyLimit, cfg)
+	return nil
+}
+
+func trendHistory(cmd *cobra.Command, historyLimit int, cfg *config.Config) error {
+	h, err := queryHistory(cmd)
+	if err != nil {
+		return err
+	}
+
+	output.ShowHistoryTrend(h, historyLimit, cfg)
+	return nil
+}
+
+// mergeHistory merges the entries of the history files into the history,
+// applying the history limit and retention rules.
+func mergeHistory(cmd *cobra.Command, files []string, historyLimit int, cfg *config.Config) error {
+	s, err := getHistoryStorage(cmd)
+	if err != nil {
+		return err
+	}
+	h, err := history.LoadStorage(s)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load history: %w", err)
+		}
+		h = history.NewWithStorage(s)
+	}
+
+	policy, _ := cmd.Flags().GetString(MergePolicyFlag)
+	merged := 0
+	for _, file := range files {
+		other, err := history.Open(file)
+		if err != nil {
+			return fmt.Errorf("failed to load history %s: %w", file, err)
+		}
+		n, err := h.Merge(other.Entries, policy)
+		if err != nil {
+			return err
+		}
+		merged += n
+	}
+	if cfg.Retention.IsSet() {
+		if _, err := h.Prune(cfg.Retention, time.Now()); err != nil {
+			return err
+		}
+	}
+	if err := h.Save(historyLimit); err != nil {
+		return fmt.Errorf("failed to save merged history: %w", err)
+	}
+
+	if !cfg.IsDocumentFormat() {
+		fmt.Printf("≡ Merged %d history entr%s from %d file%s\n", merged,
+			map[bool]string{true: "y", false: "ies"}[merged == 1], len(files),
+			map[bool]string{true: "", false: "s"}[len(files) == 1])
+	}
+	return nil
+}
+
+// pruneHistory removes the entries the retention rules do not keep, or shows
+// them without removing them when --dry-run is given.
+func pruneHistory(cmd *cobra.Command, historyLimit int, cfg *config.Config) error {
+	if !cfg.Retention.IsSet() {
+		return fmt.Errorf("--%s requires --%s, --%s, or --%s",
+			PruneHistoryFlag, RetainPerBranchFlag, RetainMaxAgeFlag, RetainThinFlag)
+	}
 	h, err := getHistory(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to load history: %w", err)
 	}
 
-	output.ShowHistory(h, historyLimit, cfg)
+	removed, err := h.Prune(cfg.Retention, time.Now())
+	if err != nil {
+		return err
+	}
+	dryRun, _ := cmd.Flags().GetBool(DryRunFlag)
+	if !dryRun && len(removed) > 0 {
+		if err := h.Save(historyLimit); err != nil {
+			return fmt.Errorf("failed to save history after pruning: %w", err)
+		}
+	}
+
+	output.ShowPrunedHistory(removed, dryRun, cfg)
 	return nil
 }
 
@@ -113,7 +370,10 @@ func deleteHistory(cmd *cobra.Command, deleteRef string, historyLimit int) error
 		return fmt.Errorf("failed to load history: %w", err)
 	}
 
-	deleted := h.DeleteByRef(deleteRef)
+	deleted, err := h.DeleteByRef(deleteRef)
+	if err != nil {
+		return err
+	}
 	if !deleted {
 		return fmt.Errorf("no history entry found for ref: %s", deleteRef)
 	}
@@ -125,13 +385,35 @@ func deleteHistory(cmd *cobra.Command, deleteRef string, historyLimit int) error
 	fmt.Printf("≡ Deleted history entry for ref: %s\n", deleteRef)
 
 	// Check if history is now empty and prompt for file removal in interactive mode
-	if len(h.Entries) == 0 {
+	if _, isFile := getHistoryFile(cmd); len(h.Entries) == 0 && isFile {
 		return promptForHistoryFileRemoval(cmd)
 	}
 
 	return nil
 }
 
+// migrateHistory copies the entries of the history file into the git notes of
+// the git-notes:// history URI.
+func migrateHistory(cmd *cobra.Command) error {
+	historyFile, isFile := getHistoryFile(cmd)
+	if !isFile {
+		return fmt.Errorf("--%s requires --%s to be a history file", MigrateHistoryFlag, HistoryFileFlag)
+	}
+
+	migrated, skipped, err := history.MigrateToNotes(historyFile, ".", history.NotesRef)
+	if err != nil {
+		return fmt.Errorf("failed to migrate history: %w", err)
+	}
+
+	fmt.Printf("≡ Migrated %d history entr%s from %s to %s\n", migrated,
+		map[bool]string{true: "y", false: "ies"}[migrated == 1], historyFile, history.NotesRef)
+	if skipped > 0 {
+		fmt.Printf("≡ Skipped %d history entr%s without a commit hash\n", skipped,
+			map[bool]string{true: "y", false: "ies"}[skipped == 1])
+	}
+	return nil
+}
+
 func promptForHistoryFileRemoval(cmd *cobra.Command) error {
 	// Only prompt if stdin is a terminal (interactive mode)
 	if !term.IsTerminal(int(os.Stdin.Fd())) { //nolint:gosec // fd fits in int on supported platforms
@@ -151,11 +433,7 @@ func promptForHistoryFileRemoval(cmd *cobra.Command) error {
 		return nil
 	}
 
-	historyPath, err := getHistoryPath(cmd)
-	if err != nil {
-		return fmt.Errorf("failed to get history path: %w", err)
-	}
-
+	historyPath, _ := getHistoryFile(cmd)
 	if err := os.Remove(historyPath); err != nil {
 		return fmt.Errorf("failed to remove history file: %w", err)
 	}
@@ -66,3 +66,23 @@ func TestExecute_Init_FileExists(t *testing.T) {
 	require.Empty(t, stdOut)
 	require.Contains(t, stdErr, ".go-covercheck.yml already exists")
 }
+
+func TestExecute_Schema(t *testing.T) {
+	cmd := newSchemaCmd()
+	cmd.SetArgs([]string{"config"})
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+	require.Contains(t, stdOut, `"$id": "https://raw.githubusercontent.com/mach6/go-covercheck/main/schemas/config.schema.json"`)
+	require.Contains(t, stdOut, `"statementThreshold"`)
+}
+
+func TestExecute_Schema_Unknown(t *testing.T) {
+	cmd := newSchemaCmd()
+	cmd.SetArgs([]string{"bogus"})
+
+	_, stdErr, err := runCmdForTest(t, cmd)
+	require.Error(t, err)
+	require.Contains(t, stdErr, `unknown schema "bogus"`)
+}
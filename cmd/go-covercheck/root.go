@@ -7,12 +7,14 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/fatih/color"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/mach6/go-covercheck/pkg/compute"
 	"github.com/mach6/go-covercheck/pkg/config"
 	"github.com/mach6/go-covercheck/pkg/filters"
+	"github.com/mach6/go-covercheck/pkg/history"
 	"github.com/mach6/go-covercheck/pkg/output"
 	"github.com/mach6/go-covercheck/samples"
 	"github.com/spf13/cobra"
@@ -89,17 +91,131 @@ const (
 	HistoryLabelFlagShort = "l"
 	HistoryLabelFlagUsage = "optional label name for history entry"
 
+	BuildMetadataFlag      = "build-metadata"
+	BuildMetadataFlagUsage = "metadata recorded with the CI build details of the entry saved by --save-history, " +
+		"given as key=value such as env=staging (repeatable)"
+
+	HistoryLinesFlag      = "history-lines"
+	HistoryLinesFlagUsage = "record the uncovered line ranges and covered block hashes of each file " +
+		"in the entry saved by --save-history, to list newly uncovered and covered lines in comparisons"
+
+	FilterBuildFlag      = "filter-build"
+	FilterBuildFlagUsage = "only use historical entries whose build field " +
+		"[provider|number|url|pullRequest|pipeline|runner|goVersion] or metadata has the value, " +
+		"given as key=value (repeatable)"
+
+	FilterBranchFlag      = "filter-branch"
+	FilterBranchFlagUsage = "only use historical entries recorded on these branches"
+
+	FilterLabelFlag      = "filter-label"
+	FilterLabelFlagUsage = "only use historical entries whose label matches this regex"
+
+	FilterTaggedFlag      = "filter-tagged"
+	FilterTaggedFlagUsage = "only use historical entries of tagged commits"
+
+	FilterCommitsFlag      = "filter-commits"
+	FilterCommitsFlagUsage = "only use historical entries of the commits in the git range from..to, " +
+		"such as v1.0.0..main"
+
+	SinceFlag      = "since"
+	SinceFlagUsage = "only use historical entries recorded since this date [e.g. 2025-07-01], " +
+		"RFC 3339 time, or age [e.g. 30d]"
+
+	UntilFlag      = "until"
+	UntilFlagUsage = "only use historical entries recorded until this date [e.g. 2025-09-30], " +
+		"RFC 3339 time, or age [e.g. 30d]"
+
+	HistoryKeyFlag      = "history-key"
+	HistoryKeyFlagUsage = "identity of a history entry replaced by --save-history [" + history.KeyCommit + "|" +
+		history.KeyCommitLabel + "]; " + history.KeyCommitLabel + " keeps an entry per --label of a commit"
+
 	CompareHistoryFlag      = "compare-history"
 	CompareHistoryFlagShort = "C"
-	CompareHistoryFlagUsage = "compare current coverage against historical ref [commit|branch|tag|label]"
+	CompareHistoryFlagUsage = "compare current coverage against historical ref [commit|branch|tag|label], " +
+		"optionally qualified as ref@label"
+
+	CompareMergeBaseFlag      = "compare-merge-base"
+	CompareMergeBaseFlagUsage = "compare current coverage against the history entry of the merge-base of HEAD and " +
+		"this target branch, or of its nearest first-parent ancestor with history"
+
+	CompareBestFlag      = "compare-best"
+	CompareBestFlagUsage = "compare current coverage against the best ever recorded in history per file, package, " +
+		"total, and metric, among the entries of the --best-scope"
+
+	BestScopeFlag      = "best-scope"
+	BestScopeFlagUsage = "entries --compare-best takes the best of [" + history.BestScopeLabelBranch + "|" +
+		history.BestScopeLabel + "|" + history.BestScopeAll + "]; " + history.BestScopeLabelBranch +
+		" keeps those of the --label on the current branch, or on the --filter-branch branches when given"
+
+	NewFileThresholdFlag      = "new-file-threshold"
+	NewFileThresholdFlagUsage = "fail when a file added since the --compare-history ref has statement, block, " +
+		"or line coverage below this percentage [0=disabled]"
+
+	FailOnRegressionFlag      = "fail-on-regression"
+	FailOnRegressionFlagUsage = "fail when coverage drops against the --compare-history ref, the " +
+		"--compare-merge-base entry, the --compare-best values, or the --regression-baseline beyond " +
+		"the --regression-tolerance"
+
+	RegressionBaselineFlag      = "regression-baseline"
+	RegressionBaselineFlagUsage = "branch whose latest history entry is the regression baseline " +
+		"when --compare-history is not given"
+
+	RegressionToleranceFlag      = "regression-tolerance"
+	RegressionToleranceFlagUsage = "allowed coverage drop in percentage points as scope[.metric]=value, " +
+		"where scope is file|package|total and metric is statements|blocks|lines [default 0]"
+
+	DiffHistoryFlag      = "diff-history"
+	DiffHistoryFlagUsage = "compare two historical refs [commit|branch|tag|label] given as from,to; " +
+		"no coverage profile is needed"
 
 	ShowHistoryFlag      = "show-history"
 	ShowHistoryFlagShort = "I"
-	ShowHistoryFlagUsage = "show historical entries in tabular format"
+	ShowHistoryFlagUsage = "show historical entries in the selected --format"
+
+	TrendHistoryFlag      = "trend-history"
+	TrendHistoryFlagUsage = "show the coverage trend of historical entries as sparklines, a chart, " +
+		"and a per-package table"
 
 	DeleteHistoryFlag      = "delete-history"
 	DeleteHistoryFlagShort = "D"
-	DeleteHistoryFlagUsage = "delete historical entry by ref [commit|branch|tag|label]"
+	DeleteHistoryFlagUsage = "delete historical entry by ref [commit|branch|tag|label], optionally qualified as ref@label"
+
+	MigrateHistoryFlag      = "migrate-history"
+	MigrateHistoryFlagUsage = "copy the entries of the --history-file into the git notes of the " +
+		history.SchemeGitNotes + ":// history URI, under " + history.NotesRef
+
+	MergeHistoryFlag      = "merge-history"
+	MergeHistoryFlagUsage = "merge the entries of these history files or URIs into the --history-file, " +
+		"given as file1,file2,..."
+
+	MergePolicyFlag      = "merge-policy"
+	MergePolicyFlagUsage = "entry kept by --merge-history when entries share a commit and label [" +
+		history.MergeNewest + "|" + history.MergeKeepBoth + "]"
+
+	PruneHistoryFlag      = "prune-history"
+	PruneHistoryFlagUsage = "remove the historical entries the --retain-* rules do not keep"
+
+	DryRunFlag      = "dry-run"
+	DryRunFlagUsage = "show the historical entries --prune-history would remove without removing them"
+
+	RetainPerBranchFlag      = "retain-per-branch"
+	RetainPerBranchFlagUsage = "keep at most this many of the newest historical entries of each branch [0=no limit]"
+
+	RetainTaggedFlag      = "retain-tagged"
+	RetainTaggedFlagUsage = "always keep historical entries of tagged commits when pruning"
+
+	RetainLabeledFlag      = "retain-labeled"
+	RetainLabeledFlagUsage = "always keep labeled historical entries when pruning"
+
+	RetainMaxAgeFlag      = "retain-max-age"
+	RetainMaxAgeFlagUsage = "drop historical entries older than this age [e.g. 90d|12w|720h]"
+
+	RetainThinFlag      = "retain-thin"
+	RetainThinFlagUsage = "keep one historical entry per " + config.ThinDay + " or " + config.ThinWeek +
+		" of each branch and label [" + config.ThinDay + "|" + config.ThinWeek + "]"
+
+	RetainThinAfterFlag      = "retain-thin-after"
+	RetainThinAfterFlagUsage = "only thin historical entries older than this age [e.g. 30d]"
 
 	HistoryLimitFlag      = "limit-history"
 	HistoryLimitFlagShort = "L"
@@ -140,6 +256,10 @@ const (
 		"monokai|dracula|solarized-dark|vim|emacs|...]; auto picks github or github-dark " +
 		"based on detected terminal background"
 
+	EnvelopeFlag      = "envelope"
+	EnvelopeFlagUsage = "wrap json/yaml output in an envelope (or lead ndjson output with a record) with run metadata " +
+		"(tool version, timestamp, git details, profile mode, config, and effective thresholds)"
+
 	// ConfigFilePermissions permissions.
 	ConfigFilePermissions = 0600
 )
@@ -147,6 +267,7 @@ const (
 // Execute the CLI application.
 func Execute() {
 	initFlags(rootCmd)
+	rootCmd.AddCommand(newSchemaCmd())
 	if err := rootCmd.Execute(); err != nil {
 		// the error message is printed by default -- just exit.
 		os.Exit(1)
@@ -157,17 +278,17 @@ func Execute() {
 var (
 	ConfigFlagDefault = "." + config.AppName + ".yml"
 
-	HistoryFileFlagUsage   = "path to " + config.AppName + " history file"
+	HistoryFileFlagUsage   = "path or URI (file://, dir://, git-notes://, git-branch://) of " + config.AppName + " history"
 	HistoryFileFlagDefault = "." + config.AppName + ".history.json"
 
 	NoTableFlagUsage = fmt.Sprintf(
-		"suppress tabular output and only show failure summary [disabled for %s|%s]",
-		config.FormatJSON, config.FormatYAML,
+		"suppress tabular output and only show failure summary [disabled for %s|%s|%s|%s]",
+		config.FormatJSON, config.FormatYAML, config.FormatNDJSON, config.FormatDashboard,
 	)
 
 	NoSummaryFlagUsage = fmt.Sprintf(
-		"suppress failure summary and only show tabular output [disabled for %s|%s]",
-		config.FormatJSON, config.FormatYAML,
+		"suppress failure summary and only show tabular output [disabled for %s|%s|%s|%s]",
+		config.FormatJSON, config.FormatYAML, config.FormatNDJSON, config.FormatDashboard,
 	)
 
 	SortByFlagUsage = fmt.Sprintf(
@@ -186,7 +307,7 @@ var (
 		config.SortOrderDesc,
 	)
 
-	FormatFlagUsage = fmt.Sprintf("output format [%s|%s|%s|%s|%s|%s|%s]",
+	FormatFlagUsage = fmt.Sprintf("output format [%s|%s|%s|%s|%s|%s|%s|%s|%s]",
 		config.FormatTable,
 		config.FormatJSON,
 		config.FormatYAML,
@@ -194,6 +315,8 @@ var (
 		config.FormatHTML,
 		config.FormatCSV,
 		config.FormatTSV,
+		config.FormatNDJSON,
+		config.FormatDashboard,
 	)
 
 	SkipFlagDefault []string
@@ -224,7 +347,7 @@ func run(cmd *cobra.Command, args []string) error {
 	}
 
 	// showCoverage and get the results.
-	results, failed, err := showCoverage(args, cfg)
+	results, files, failed, err := showCoverage(cmd, args, cfg)
 	if err != nil {
 		return err
 	}
@@ -234,8 +357,8 @@ func run(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// handle history operations (compare and save)
-	if err := handleHistoryOperations(cmd, results, cfg); err != nil {
+	// handle history operations (save)
+	if err := handleHistoryOperations(cmd, results, files, cfg); err != nil {
 		return err
 	}
 
@@ -254,12 +377,37 @@ func handleNonCoverageOperationsWhichShouldExit(cmd *cobra.Command, cfg *config.
 
 	historyLimit, _ := cmd.Flags().GetInt(HistoryLimitFlag)
 
+	// migrate the history file into git notes and exit when requested.
+	if bMigrate, _ := cmd.Flags().GetBool(MigrateHistoryFlag); bMigrate {
+		return true, migrateHistory(cmd)
+	}
+
+	// merge history files and exit when requested.
+	if mergeFiles, _ := cmd.Flags().GetStringSlice(MergeHistoryFlag); len(mergeFiles) > 0 {
+		return true, mergeHistory(cmd, mergeFiles, historyLimit, cfg)
+	}
+
+	// prune history and exit when requested.
+	if bPrune, _ := cmd.Flags().GetBool(PruneHistoryFlag); bPrune {
+		return true, pruneHistory(cmd, historyLimit, cfg)
+	}
+
 	// delete history entry and exit when requested.
 	deleteRef, _ := cmd.Flags().GetString(DeleteHistoryFlag)
 	if deleteRef != "" {
 		return true, deleteHistory(cmd, deleteRef, historyLimit)
 	}
 
+	// compare two history entries and exit when requested.
+	if diffRefs, _ := cmd.Flags().GetStringSlice(DiffHistoryFlag); len(diffRefs) > 0 {
+		return true, diffHistory(cmd, diffRefs, cfg)
+	}
+
+	// show the history trend and exit when requested.
+	if bTrendHistory, _ := cmd.Flags().GetBool(TrendHistoryFlag); bTrendHistory {
+		return true, trendHistory(cmd, historyLimit, cfg)
+	}
+
 	// show history and exit when requested.
 	bShowHistory, _ := cmd.Flags().GetBool(ShowHistoryFlag)
 	if bShowHistory {
@@ -268,32 +416,69 @@ func handleNonCoverageOperationsWhichShouldExit(cmd *cobra.Command, cfg *config.
 	return false, nil
 }
 
-func showCoverage(args []string, cfg *config.Config) (compute.Results, bool, error) {
+func showCoverage(cmd *cobra.Command, args []string, cfg *config.Config) (
+	compute.Results, []history.FileLines, bool, error) {
 	// we need coverage profile input from here on.
 	profiles, err := getCoverProfileData(args)
 	if err != nil {
-		return compute.Results{}, false, err
+		return compute.Results{}, nil, false, err
 	}
 	// Normalize filenames once up front so skip regexes, diff-from filtering,
 	// per-file/per-package threshold overrides, the tabular/structured
 	// reporting path, and --inspect/--inspect-file matching all operate on
 	// the same module-relative paths.
-	compute.NormalizeNames(profiles, cfg)
+	moduleName := compute.NormalizeNames(profiles, cfg)
 	filtered := filters.FilterProfiles(profiles, cfg)
 
 	// If inspecting uncovered lines, handle that separately
 	if cfg.Inspect {
 		err := output.InspectUncoveredLines(filtered, cfg)
 		if err != nil {
-			return compute.Results{}, false, err
+			return compute.Results{}, nil, false, err
 		}
 		// For uncovered lines mode, we don't need to return results or failure status
-		return compute.Results{}, false, nil
+		return compute.Results{}, nil, false, nil
 	}
 
 	results, failed := compute.CollectResults(filtered, cfg)
-	output.FormatAndReport(results, cfg, failed)
-	return results, failed, nil
+	var meta *output.Metadata
+	if cfg.Envelope {
+		m := output.NewMetadata(cfg, ".", profileMode(profiles), moduleName)
+		meta = &m
+	}
+	// the line detail of the files reads their sources, so it is only collected
+	// when a comparison or --history-lines needs it.
+	files := sync.OnceValue(func() []history.FileLines {
+		return history.CollectFileLines(filtered)
+	})
+	// compare against history before reporting so the comparison is rendered in
+	// the selected format; a failed comparison still reports the results.
+	comparison, compareErr := compareHistory(cmd, results, files, cfg)
+	if comparison != nil {
+		comparison.ApplyNewFileThreshold(cfg.NewFileThreshold)
+		if cfg.Regression.Fail {
+			comparison.ApplyRegressionTolerance(cfg.Regression.Tolerance)
+		}
+	}
+	output.FormatAndReportWithComparison(results, comparison, meta, cfg, failed)
+	var savedFiles []history.FileLines
+	if bHistoryLines, _ := cmd.Flags().GetBool(HistoryLinesFlag); bHistoryLines {
+		savedFiles = files()
+	}
+	if compareErr != nil {
+		return results, savedFiles, failed, compareErr
+	}
+	return results, savedFiles, failed || (comparison != nil && comparison.Failed()), nil
+}
+
+// profileMode returns the cover mode (set, count, or atomic) of the profiles.
+func profileMode(profiles []*cover.Profile) string {
+	for _, p := range profiles {
+		if p.Mode != "" {
+			return p.Mode
+		}
+	}
+	return ""
 }
 
 func getCoverProfileData(args []string) ([]*cover.Profile, error) {
@@ -468,6 +653,9 @@ func getConfig(cmd *cobra.Command) (*config.Config, error) {
 	}
 
 	applyConfigOverrides(cfg, cmd, noConfigFile)
+	if err := applyRegressionToleranceFlag(cmd, cfg); err != nil {
+		return cfg, err
+	}
 
 	if err := cfg.Validate(); err != nil {
 		return cfg, err
@@ -493,6 +681,16 @@ func applyConfigOverrides(cfg *config.Config, cmd *cobra.Command, noConfigFile b
 	applyBoolFlagOverride(cmd, NoSummaryFlag, &cfg.NoSummary, noConfigFile)
 	applyBoolFlagOverride(cmd, NoColorFlag, &cfg.NoColor, noConfigFile)
 	applyBoolFlagOverride(cmd, NoUncoveredLinesFlag, &cfg.NoUncoveredLines, noConfigFile)
+	applyBoolFlagOverride(cmd, EnvelopeFlag, &cfg.Envelope, noConfigFile)
+	applyFloat64FlagOverride(cmd, NewFileThresholdFlag, &cfg.NewFileThreshold, noConfigFile)
+	applyBoolFlagOverride(cmd, FailOnRegressionFlag, &cfg.Regression.Fail, noConfigFile)
+	applyStringFlagOverride(cmd, RegressionBaselineFlag, &cfg.Regression.Baseline, noConfigFile)
+	applyIntFlagOverride(cmd, RetainPerBranchFlag, &cfg.Retention.PerBranch, noConfigFile)
+	applyBoolFlagOverride(cmd, RetainTaggedFlag, &cfg.Retention.KeepTagged, noConfigFile)
+	applyBoolFlagOverride(cmd, RetainLabeledFlag, &cfg.Retention.KeepLabeled, noConfigFile)
+	applyStringFlagOverride(cmd, RetainMaxAgeFlag, &cfg.Retention.MaxAge, noConfigFile)
+	applyStringFlagOverride(cmd, RetainThinFlag, &cfg.Retention.Thin, noConfigFile)
+	applyStringFlagOverride(cmd, RetainThinAfterFlag, &cfg.Retention.ThinAfter, noConfigFile)
 	applyBoolFlagOverride(cmd, InspectFlag, &cfg.Inspect, true)
 	if len(cfg.InspectFiles) > 0 {
 		cfg.Inspect = true
@@ -517,6 +715,18 @@ func applyConfigOverrides(cfg *config.Config, cmd *cobra.Command, noConfigFile b
 	}
 }
 
+// applyRegressionToleranceFlag sets each --regression-tolerance on top of the
+// tolerances from the config file.
+func applyRegressionToleranceFlag(cmd *cobra.Command, cfg *config.Config) error {
+	specs, _ := cmd.Flags().GetStringArray(RegressionToleranceFlag)
+	for _, spec := range specs {
+		if err := cfg.Regression.Tolerance.Set(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func applyFloat64FlagOverride(cmd *cobra.Command, flagName string, target *float64, noConfigFile bool) {
 	if v, _ := cmd.Flags().GetFloat64(flagName); cmd.Flags().Changed(flagName) || noConfigFile {
 		*target = v
@@ -699,6 +909,66 @@ func initFlags(cmd *cobra.Command) {
 		HistoryLabelFlagUsage,
 	)
 
+	cmd.Flags().StringArray(
+		BuildMetadataFlag,
+		nil,
+		BuildMetadataFlagUsage,
+	)
+
+	cmd.Flags().Bool(
+		HistoryLinesFlag,
+		false,
+		HistoryLinesFlagUsage,
+	)
+
+	cmd.Flags().StringArray(
+		FilterBuildFlag,
+		nil,
+		FilterBuildFlagUsage,
+	)
+
+	cmd.Flags().StringSlice(
+		FilterBranchFlag,
+		nil,
+		FilterBranchFlagUsage,
+	)
+
+	cmd.Flags().String(
+		FilterLabelFlag,
+		"",
+		FilterLabelFlagUsage,
+	)
+
+	cmd.Flags().Bool(
+		FilterTaggedFlag,
+		false,
+		FilterTaggedFlagUsage,
+	)
+
+	cmd.Flags().String(
+		FilterCommitsFlag,
+		"",
+		FilterCommitsFlagUsage,
+	)
+
+	cmd.Flags().String(
+		SinceFlag,
+		"",
+		SinceFlagUsage,
+	)
+
+	cmd.Flags().String(
+		UntilFlag,
+		"",
+		UntilFlagUsage,
+	)
+
+	cmd.Flags().String(
+		HistoryKeyFlag,
+		history.KeyCommit,
+		HistoryKeyFlagUsage,
+	)
+
 	cmd.Flags().StringP(
 		CompareHistoryFlag,
 		CompareHistoryFlagShort,
@@ -706,6 +976,24 @@ func initFlags(cmd *cobra.Command) {
 		CompareHistoryFlagUsage,
 	)
 
+	cmd.Flags().String(
+		CompareMergeBaseFlag,
+		"",
+		CompareMergeBaseFlagUsage,
+	)
+
+	cmd.Flags().Bool(
+		CompareBestFlag,
+		false,
+		CompareBestFlagUsage,
+	)
+
+	cmd.Flags().String(
+		BestScopeFlag,
+		history.BestScopeLabelBranch,
+		BestScopeFlagUsage,
+	)
+
 	cmd.Flags().BoolP(
 		ShowHistoryFlag,
 		ShowHistoryFlagShort,
@@ -713,6 +1001,12 @@ func initFlags(cmd *cobra.Command) {
 		ShowHistoryFlagUsage,
 	)
 
+	cmd.Flags().Bool(
+		TrendHistoryFlag,
+		false,
+		TrendHistoryFlagUsage,
+	)
+
 	cmd.Flags().StringP(
 		DeleteHistoryFlag,
 		DeleteHistoryFlagShort,
@@ -720,6 +1014,72 @@ func initFlags(cmd *cobra.Command) {
 		DeleteHistoryFlagUsage,
 	)
 
+	cmd.Flags().Bool(
+		MigrateHistoryFlag,
+		false,
+		MigrateHistoryFlagUsage,
+	)
+
+	cmd.Flags().StringSlice(
+		MergeHistoryFlag,
+		nil,
+		MergeHistoryFlagUsage,
+	)
+
+	cmd.Flags().String(
+		MergePolicyFlag,
+		history.MergeNewest,
+		MergePolicyFlagUsage,
+	)
+
+	cmd.Flags().Bool(
+		PruneHistoryFlag,
+		false,
+		PruneHistoryFlagUsage,
+	)
+
+	cmd.Flags().Bool(
+		DryRunFlag,
+		false,
+		DryRunFlagUsage,
+	)
+
+	cmd.Flags().Int(
+		RetainPerBranchFlag,
+		0,
+		RetainPerBranchFlagUsage,
+	)
+
+	cmd.Flags().Bool(
+		RetainTaggedFlag,
+		false,
+		RetainTaggedFlagUsage,
+	)
+
+	cmd.Flags().Bool(
+		RetainLabeledFlag,
+		false,
+		RetainLabeledFlagUsage,
+	)
+
+	cmd.Flags().String(
+		RetainMaxAgeFlag,
+		"",
+		RetainMaxAgeFlagUsage,
+	)
+
+	cmd.Flags().String(
+		RetainThinFlag,
+		"",
+		RetainThinFlagUsage,
+	)
+
+	cmd.Flags().String(
+		RetainThinAfterFlag,
+		"",
+		RetainThinAfterFlagUsage,
+	)
+
 	cmd.Flags().IntP(
 		HistoryLimitFlag,
 		HistoryLimitFlagShort,
@@ -781,6 +1141,42 @@ func initFlags(cmd *cobra.Command) {
 		"",
 		DiffFromFlagUsage,
 	)
+
+	cmd.Flags().Bool(
+		EnvelopeFlag,
+		false,
+		EnvelopeFlagUsage,
+	)
+
+	cmd.Flags().Float64(
+		NewFileThresholdFlag,
+		config.NewFileThresholdDefault,
+		NewFileThresholdFlagUsage,
+	)
+
+	cmd.Flags().StringSlice(
+		DiffHistoryFlag,
+		nil,
+		DiffHistoryFlagUsage,
+	)
+
+	cmd.Flags().Bool(
+		FailOnRegressionFlag,
+		false,
+		FailOnRegressionFlagUsage,
+	)
+
+	cmd.Flags().String(
+		RegressionBaselineFlag,
+		"",
+		RegressionBaselineFlagUsage,
+	)
+
+	cmd.Flags().StringArray(
+		RegressionToleranceFlag,
+		nil,
+		RegressionToleranceFlagUsage,
+	)
 }
 
 func initConfigFile(cmd *cobra.Command) error {
@@ -2,13 +2,21 @@ package main
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
 	"github.com/mach6/go-covercheck/pkg/compute"
 	"github.com/mach6/go-covercheck/pkg/config"
 	"github.com/mach6/go-covercheck/pkg/filters"
 	"github.com/mach6/go-covercheck/pkg/history"
+	"github.com/mach6/go-covercheck/pkg/output"
 	"github.com/mach6/go-covercheck/pkg/test"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
@@ -181,6 +189,167 @@ func Test_run_SaveHistory(t *testing.T) {
 	require.Len(t, h.Entries, 2)
 }
 
+func Test_run_SaveHistory_KeyCommitLabel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	coverage := test.CreateTempCoverageFile(t, test.TestCoverageOut)
+	for _, label := range []string{"unit", "integration", "unit"} {
+		cmd := setupTestCmd()
+		cmd.SetArgs([]string{
+			"--history-file", path, "--save-history", "--label", label, "--history-key", "commit+label",
+			"-w", "-s", "1", "-b", "1", "-n", "1", coverage,
+		})
+		_, _, err := runCmdForTest(t, cmd)
+		require.NoError(t, err)
+	}
+
+	h, err := history.Load(path)
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 2)
+	require.Equal(t, "unit", h.Entries[0].Label)
+	require.Equal(t, "integration", h.Entries[1].Label)
+
+	ref := h.Entries[1].Ref()
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path, "--compare-history", ref, "-w", "-s", "1", "-b", "1", "-n", "1", coverage,
+	})
+	stdOut, _, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Contains(t, stdOut, "≡ Comparing against ref: "+ref+" [commit "+ref+"]")
+}
+
+func Test_run_SaveHistory_BuildMetadata(t *testing.T) {
+	t.Setenv("GOVERSION", "go1.26.0")
+	path := filepath.Join(t.TempDir(), "history.json")
+	coverage := test.CreateTempCoverageFile(t, test.TestCoverageOut)
+	for _, env := range []string{"staging", "prod"} {
+		cmd := setupTestCmd()
+		cmd.SetArgs([]string{
+			"--history-file", path, "--save-history", "--label", env, "--history-key", "commit+label",
+			"--build-metadata", "env=" + env, "-w", "-s", "1", "-b", "1", "-n", "1", coverage,
+		})
+		_, _, err := runCmdForTest(t, cmd)
+		require.NoError(t, err)
+	}
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path, "--show-history", "--filter-build", "env=prod", "--format", "json", "--no-color",
+	})
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+
+	var h history.History
+	require.NoError(t, json.Unmarshal([]byte(stdOut), &h))
+	require.Len(t, h.Entries, 1)
+	require.Equal(t, "prod", h.Entries[0].Label)
+	require.Equal(t, map[string]string{"env": "prod"}, h.Entries[0].Build.Metadata)
+	require.Equal(t, "go1.26.0", h.Entries[0].Build.GoVersion)
+
+	cmd = setupTestCmd()
+	cmd.SetArgs([]string{"--history-file", path, "--show-history", "--filter-build", "env"})
+	_, _, err = runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, `--filter-build: invalid pair "env", expected key=value`)
+}
+
+func Test_run_SaveHistory_Lines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	coverage := test.CreateTempCoverageFile(t, test.TestCoverageOut)
+	for _, label := range []string{"aggregate", "lines"} {
+		cmd := setupTestCmd()
+		cmd.SetArgs([]string{
+			"--history-file", path, "--save-history", "--label", label, "--history-key", "commit+label",
+			"--history-lines=" + strconv.FormatBool(label == "lines"), "-w", "-s", "1", "-b", "1", "-n", "1", coverage,
+		})
+		_, _, err := runCmdForTest(t, cmd)
+		require.NoError(t, err)
+	}
+
+	h, err := history.Load(path)
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 2)
+	for _, entry := range h.Entries {
+		if entry.Label == "aggregate" {
+			require.Empty(t, entry.Files)
+			continue
+		}
+		require.Equal(t, []history.FileLines{{File: entry.Results.ByFile[0].File, Uncovered: "7-8"}}, entry.Files)
+	}
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path, "--compare-history", "unknown@lines", "--format", "json", "--no-color",
+		"-w", "-s", "1", "-b", "1", "-n", "1", coverage,
+	})
+	stdOut, _, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Contains(t, stdOut, `"comparison"`)
+	require.NotContains(t, stdOut, `"newlyUncovered"`)
+}
+
+func Test_run_CompareHistory_NewlyUncoveredLines(t *testing.T) {
+	const source = "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\n" +
+		"func Sub(a, b int) int {\n\treturn a - b\n}\n"
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	commit := func(name, content string) string {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0o600))
+		_, err := w.Add(name)
+		require.NoError(t, err)
+		hash, err := w.Commit("commit "+name, &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return hash.String()
+	}
+	commit("go.mod", "module example.com/calc\n")
+	saved := commit("calc.go", source)
+	t.Chdir(repoDir)
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path, "--save-history", "--history-lines", "-w", "-s", "1", "-b", "1", "-n", "1",
+		test.CreateTempCoverageFile(t, "mode: set\n"+
+			"example.com/calc/calc.go:3.24,5.2 1 1\nexample.com/calc/calc.go:7.24,9.2 1 1\n"),
+	})
+	_, _, err = runCmdForTest(t, cmd)
+	require.NoError(t, err)
+
+	// two lines inserted before Add move Sub, which lost its coverage, to 9-11
+	commit("calc.go", "package calc\n\n// Add adds a and b.\n// Sub subtracts them.\n"+source[len("package calc\n\n"):])
+	cmd = setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path, "--compare-history", saved[:7], "--format", "json", "--no-color",
+		"-w", "-s", "1", "-b", "1", "-n", "1",
+		test.CreateTempCoverageFile(t, "mode: set\n"+
+			"example.com/calc/calc.go:5.24,7.2 1 1\nexample.com/calc/calc.go:9.24,11.2 1 0\n"),
+	})
+	stdOut, _, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+
+	var results struct {
+		Comparison history.Comparison `json:"comparison"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdOut), &results))
+	require.Equal(t, []history.LineChange{{File: "calc.go", NewlyUncovered: "9-10"}}, results.Comparison.Lines)
+}
+
+func Test_run_SaveHistory_InvalidKey(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", filepath.Join(t.TempDir(), "history.json"), "--save-history", "--history-key", "label",
+		"-w", "-s", "1", "-b", "1", "-n", "1", test.CreateTempCoverageFile(t, test.TestCoverageOut),
+	})
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, `invalid history key "label"`)
+}
+
 func Test_run_SaveHistory_NoPreviousFile(t *testing.T) {
 	path := t.TempDir() + ".go-covercheck.history.json"
 
@@ -318,6 +487,382 @@ func Test_run_CompareHistoryFailsBadRef(t *testing.T) {
 	require.ErrorContains(t, err, "no history entry found for ref: unknown")
 }
 
+func Test_run_CompareHistory_JSON(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path,
+		"--compare-history", "main", "-w", "-f", "json",
+		"-s", "1", "-b", "1", "-S", "2", "-B", "2",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+	require.NotContains(t, stdOut, "Comparing against ref")
+
+	r := new(output.Report)
+	require.NoError(t, json.Unmarshal([]byte(stdOut), r))
+	require.NotEmpty(t, r.ByFile)
+	require.NotNil(t, r.Comparison)
+	require.Equal(t, "main", r.Comparison.Ref)
+	require.Equal(t, "e40262964cc463a18753e2834c04230c2a356f20", r.Comparison.Commit)
+	require.Len(t, r.Comparison.ByFile, 1)
+	require.InDelta(t, -25.0, r.Comparison.ByFile[0].Statements.Delta, 0.001)
+	require.InDelta(t, 75.0, r.Comparison.ByFile[0].Statements.Old, 0.001)
+	require.InDelta(t, 50.0, r.Comparison.ByFile[0].Statements.New, 0.001)
+	require.Nil(t, r.Comparison.ByTotal.Lines)
+}
+
+func Test_run_CompareHistory_YAMLEnvelope(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path,
+		"--compare-history", "main", "-w", "-f", "yaml", "--envelope",
+		"-s", "1", "-b", "1", "-S", "2", "-B", "2",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+
+	env := new(output.Envelope)
+	require.NoError(t, yaml.Unmarshal([]byte(stdOut), env))
+	require.NotEmpty(t, env.Results.ByFile)
+	require.NotNil(t, env.Comparison)
+	require.Equal(t, "main", env.Comparison.Branch)
+	require.InDelta(t, 22.2, env.Comparison.ByTotal.Statements.Delta, 0.1)
+}
+
+func Test_run_FailOnRegressionRequiresRef(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path,
+		"--fail-on-regression", "-w",
+		"-s", "1", "-b", "1", "-S", "2", "-B", "2",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err,
+		"--fail-on-regression requires --compare-history, --compare-merge-base, --compare-best, or --regression-baseline")
+}
+
+func Test_run_CompareMergeBaseWithCompareHistory(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path,
+		"--compare-history", "main", "--compare-merge-base", "main", "-w",
+		"-s", "1", "-b", "1", "-S", "2", "-B", "2",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, "--compare-merge-base cannot be combined with --compare-history")
+}
+
+func Test_run_FailOnRegression_BaselineWithinTolerance(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path,
+		"--fail-on-regression", "--regression-baseline", "main",
+		"--regression-tolerance", "file=25", "--regression-tolerance", "package=25",
+		"-w", "-f", "json",
+		"-s", "1", "-b", "1", "-S", "2", "-B", "2",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+
+	r := new(output.Report)
+	require.NoError(t, json.Unmarshal([]byte(stdOut), r))
+	require.NotNil(t, r.Comparison)
+	require.Equal(t, "main", r.Comparison.Ref)
+	require.Empty(t, r.Comparison.Regressions)
+}
+
+func Test_run_FailOnRegression_BadBaseline(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path,
+		"--fail-on-regression", "--regression-baseline", "release", "-w",
+		"-s", "1", "-b", "1", "-S", "2", "-B", "2",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, "no history entry found for regression baseline branch: release")
+}
+
+func Test_run_CompareBest(t *testing.T) {
+	path := createTempDiffHistoryFile(t)
+	file := "github.com/mach6/go-covercheck/pkg/math/math.go"
+
+	// an integration suite run covers more than the unlabeled runs compared
+	h, err := history.Load(path)
+	require.NoError(t, err)
+	integration := h.Entries[0]
+	integration.Label = "integration"
+	integration.Results.ByFile = slices.Clone(integration.Results.ByFile)
+	integration.Results.ByFile[0].StatementPercentage += 5
+	h.Entries = append([]history.Entry{integration}, h.Entries...)
+	require.NoError(t, h.Save(0))
+
+	tests := []struct {
+		name      string
+		args      []string
+		bestOf    int
+		shortfall float64
+	}{
+		{name: "all branches", bestOf: 2, shortfall: -30},
+		{name: "main", args: []string{"--filter-branch", "main"}, bestOf: 1, shortfall: -25},
+		{
+			name:   "integration",
+			args:   []string{"--label", "integration", "--regression-tolerance", "file=35"},
+			bestOf: 1, shortfall: -35,
+		},
+		{
+			name:   "all labels",
+			args:   []string{"--best-scope", "all", "--regression-tolerance", "file=35"},
+			bestOf: 3, shortfall: -35,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := setupTestCmd()
+			cmd.SetArgs(append([]string{
+				"--history-file", path, "--compare-best", "--fail-on-regression",
+				"--regression-tolerance", "file=30", "--regression-tolerance", "package=25",
+				"-w", "-f", "json", "--no-color", "-s", "1", "-b", "1", "-S", "2", "-B", "2",
+				test.CreateTempCoverageFile(t, test.TestCoverageOut),
+			}, tt.args...))
+			stdOut, stdErr, err := runCmdForTest(t, cmd)
+			require.NoError(t, err)
+			require.Empty(t, stdErr)
+
+			r := new(output.Report)
+			require.NoError(t, json.Unmarshal([]byte(stdOut), r))
+			require.NotNil(t, r.Comparison)
+			require.Equal(t, history.BestRef, r.Comparison.Ref)
+			require.Equal(t, tt.bestOf, r.Comparison.BestOf)
+			require.Equal(t, file, r.Comparison.ByFile[0].File)
+			require.InDelta(t, tt.shortfall, r.Comparison.ByFile[0].Statements.Delta, 0.001)
+			require.Empty(t, r.Comparison.RemovedFiles)
+			require.Empty(t, r.Comparison.Regressions)
+		})
+	}
+}
+
+func Test_run_CompareBest_InvalidScope(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", test.CreateTempHistoryFile(t, test.TestCoverageHistory),
+		"--compare-best", "--best-scope", "suite", "-w",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, `--best-scope: invalid best scope "suite"`)
+}
+
+func Test_run_CompareBestWithCompareHistory(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", test.CreateTempHistoryFile(t, test.TestCoverageHistory),
+		"--compare-best", "--compare-history", "main", "-w",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, "--compare-best cannot be combined with --compare-history or --compare-merge-base")
+}
+
+func Test_run_RegressionToleranceInvalid(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--regression-tolerance", "module=1",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, "scope must be one of file|package|total")
+}
+
+// createTempDiffHistoryFile writes a history file holding the fixture entry on
+// main and a later release entry tagged v1.1.0 with higher statement coverage.
+func createTempDiffHistoryFile(t *testing.T) string {
+	t.Helper()
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+	h, err := history.Load(path)
+	require.NoError(t, err)
+
+	release := h.Entries[0]
+	release.Commit = "f00dbabef00dbabef00dbabef00dbabef00dbabe"
+	release.Branch = "release"
+	release.Tags = []string{"v1.1.0"}
+	release.Timestamp = release.Timestamp.Add(time.Hour)
+	release.Results.ByFile = slices.Clone(release.Results.ByFile)
+	release.Results.ByFile[0].StatementPercentage += 5
+	release.Results.ByTotal.Statements.Percentage += 2
+	h.Entries = append([]history.Entry{release}, h.Entries...)
+	require.NoError(t, h.Save(0))
+	return path
+}
+
+func Test_run_ShowHistory_Query(t *testing.T) {
+	path := createTempDiffHistoryFile(t)
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"--filter-tagged"}, want: []string{"release"}},
+		{args: []string{"--filter-branch", "main,release"}, want: []string{"release", "main"}},
+		{args: []string{"--filter-branch", "main", "--since", "2025-07-01", "--until", "2025-07-18"}, want: []string{"main"}},
+		{args: []string{"--since", "2025-07-18T09:00:00Z"}, want: []string{"release"}},
+		{args: []string{"--until", "2025-07-17"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			cmd := setupTestCmd()
+			cmd.SetArgs(append([]string{"--history-file", path, "--show-history", "-f", "json", "--no-color"},
+				tt.args...))
+			stdOut, stdErr, err := runCmdForTest(t, cmd)
+			require.NoError(t, err)
+			require.Empty(t, stdErr)
+
+			var h history.History
+			require.NoError(t, json.Unmarshal([]byte(stdOut), &h))
+			var branches []string
+			for _, entry := range h.Entries {
+				branches = append(branches, entry.Branch)
+			}
+			require.Equal(t, tt.want, branches)
+		})
+	}
+}
+
+func Test_run_DiffHistory_Query(t *testing.T) {
+	path := createTempDiffHistoryFile(t)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{"--history-file", path, "--diff-history", "main,v1.1.0", "--filter-branch", "main", "-w"})
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, "no history entry found for ref: v1.1.0")
+
+	cmd = setupTestCmd()
+	cmd.SetArgs([]string{"--history-file", path, "--show-history", "--since", "last week"})
+	_, _, err = runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, `invalid since: "last week" is not a date`)
+}
+
+func Test_run_DiffHistory(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", createTempDiffHistoryFile(t),
+		"--diff-history", "main,v1.1.0", "-w",
+	})
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+	require.Contains(t, stdOut, "≡ Comparing ref: v1.1.0 [commit f00dbab] against ref: main [commit e402629]")
+	require.Contains(t, stdOut, "[S] github.com/mach6/go-covercheck/pkg/math/math.go [+5.0 %]")
+	require.Contains(t, stdOut, "[S] total [+2.0 %]")
+	require.NotContains(t, stdOut, "[B]")
+}
+
+func Test_run_DiffHistory_JSON(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", createTempDiffHistoryFile(t),
+		"--diff-history", "v1.1.0,main", "-f", "json", "-w",
+	})
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+
+	c := new(history.Comparison)
+	require.NoError(t, json.Unmarshal([]byte(stdOut), c))
+	require.Equal(t, "v1.1.0", c.Ref)
+	require.Equal(t, "release", c.Branch)
+	require.NotNil(t, c.Target)
+	require.Equal(t, "main", c.Target.Ref)
+	require.Equal(t, "e40262964cc463a18753e2834c04230c2a356f20", c.Target.Commit)
+	require.InDelta(t, -2.0, c.ByTotal.Statements.Delta, 0.001)
+	require.Len(t, c.ByFile, 1)
+}
+
+func Test_run_DiffHistory_Errors(t *testing.T) {
+	tests := map[string]struct {
+		args []string
+		err  string
+	}{
+		"one ref": {
+			args: []string{"--diff-history", "main"},
+			err:  "--diff-history requires two refs given as from,to",
+		},
+		"unknown from": {
+			args: []string{"--diff-history", "nope,main"},
+			err:  "no history entry found for ref: nope",
+		},
+		"unknown to": {
+			args: []string{"--diff-history", "main,nope"},
+			err:  "no history entry found for ref: nope",
+		},
+		"dashboard": {
+			args: []string{"--diff-history", "main,v1.1.0", "-f", "dashboard"},
+			err:  "--diff-history does not support the dashboard format",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := setupTestCmd()
+			cmd.SetArgs(append([]string{"--history-file", createTempDiffHistoryFile(t)}, tc.args...))
+			_, _, err := runCmdForTest(t, cmd)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func Test_run_Envelope(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--envelope", "-w", "-f", "json",
+		"-s", "0", "-b", "0", "-n", "0",
+		"-m", "github.com/mach6/go-covercheck",
+		test.CreateTempCoverageFile(t, test.TestCoverageOut)},
+	)
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+
+	env := new(output.Envelope)
+	err = json.Unmarshal([]byte(extractJSONFromOutput(stdOut)), env)
+	require.NoError(t, err)
+	require.Equal(t, output.EnvelopeSchemaVersion, env.SchemaVersion)
+	require.Equal(t, config.AppName, env.Metadata.Tool)
+	require.Equal(t, "set", env.Metadata.ProfileMode)
+	require.Equal(t, "github.com/mach6/go-covercheck", env.Metadata.ModuleName)
+	require.NotEmpty(t, env.Results.ByFile)
+}
+
 func Test_run_WithConfigFile(t *testing.T) {
 	path := test.CreateTempConfigFile(t, test.TestConfig)
 	cmd := setupTestCmd()
@@ -408,6 +953,89 @@ func Test_run_DeleteHistory(t *testing.T) {
 	require.Empty(t, h.Entries)
 }
 
+func Test_run_MergeHistory(t *testing.T) {
+	shard := createTempDiffHistoryFile(t)
+	target := filepath.Join(t.TempDir(), "history.json")
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", target, "-w",
+		"--merge-history", shard + "," + test.CreateTempHistoryFile(t, test.TestCoverageHistory),
+	})
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+	require.Contains(t, stdOut, "≡ Merged 2 history entries from 2 files")
+
+	h, err := history.Load(target)
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 2)
+	require.Equal(t, []string{"v1.1.0"}, h.Entries[0].Tags)
+
+	cmd = setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", target, "--merge-history", shard, "--merge-policy", "oldest",
+	})
+	_, _, err = runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, `invalid history merge policy "oldest"`)
+}
+
+func Test_run_PruneHistory(t *testing.T) {
+	path := createTempDiffHistoryFile(t)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path, "--prune-history", "--dry-run", "--retain-max-age", "30d", "--retain-tagged", "-w",
+	})
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+	require.Contains(t, stdOut, "[−] e402629 [main ")
+	require.NotContains(t, stdOut, "f00dbab")
+	require.Contains(t, stdOut, "≡ Would prune 1 history entry")
+
+	h, err := history.Load(path)
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 2)
+
+	cmd = setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path, "--prune-history", "--retain-max-age", "30d", "--retain-tagged", "-w",
+	})
+	stdOut, _, err = runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Contains(t, stdOut, "≡ Pruned 1 history entry")
+
+	h, err = history.Load(path)
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 1)
+	require.Equal(t, []string{"v1.1.0"}, h.Entries[0].Tags)
+}
+
+func Test_run_PruneHistory_NoRules(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{"--history-file", createTempDiffHistoryFile(t), "--prune-history", "--retain-tagged"})
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err,
+		"--prune-history requires --retain-per-branch, --retain-max-age, or --retain-thin")
+}
+
+func Test_run_SaveHistory_Retention(t *testing.T) {
+	path := createTempDiffHistoryFile(t)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path, "--save-history", "--retain-max-age", "30d",
+		"-w", "-s", "1", "-b", "1", "-n", "1", test.CreateTempCoverageFile(t, test.TestCoverageOut),
+	})
+	_, _, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+
+	h, err := history.Load(path)
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 1)
+}
+
 func Test_run_DeleteHistoryFails_BadRef(t *testing.T) {
 	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
 
@@ -466,3 +1094,132 @@ func TestApplyConfigOverrides_TableStyleNoConfigFile(t *testing.T) {
 	applyConfigOverrides(cfg, cmd, true)
 	require.Equal(t, config.TableStyleLight, cfg.TableStyle)
 }
+
+func Test_run_TrendHistory(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path,
+		"--trend-history",
+	})
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+	require.Contains(t, stdOut, "≡ Total coverage trend [2025-07-18]")
+	require.Contains(t, stdOut, "github.com/mach6/go-covercheck/pkg/math")
+	require.Contains(t, stdOut, "Showing trend over last 1 history entry")
+}
+
+func Test_run_ShowHistory_JSON(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", path,
+		"--show-history",
+		"--format", "json",
+		"--no-color",
+	})
+
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+
+	var h history.History
+	require.NoError(t, json.Unmarshal([]byte(stdOut), &h))
+	require.Len(t, h.Entries, 1)
+	require.Equal(t, "main", h.Entries[0].Branch)
+}
+
+func Test_run_MigrateHistoryToNotes(t *testing.T) {
+	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
+	repoDir := t.TempDir()
+	_, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	t.Chdir(repoDir)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{"--history-file", path, "--migrate-history"})
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+	require.Contains(t, stdOut, "≡ Migrated 1 history entry from "+path+" to refs/notes/covercheck")
+
+	cmd = setupTestCmd()
+	cmd.SetArgs([]string{"--history-file", "git-notes://", "--show-history"})
+	stdOut, stdErr, err = runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+	require.Contains(t, stdOut, "e402629")
+	require.Contains(t, stdOut, "Showing last 1 history entry")
+}
+
+func Test_run_HistoryBranch(t *testing.T) {
+	coverage := test.CreateTempCoverageFile(t, test.TestCoverageOut)
+	repoDir := t.TempDir()
+	_, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	t.Chdir(repoDir)
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", "git-branch://" + history.Branch, "--save-history", "--label", "ci", "-w",
+		"-s", "50", "-b", "50", "-B", "2", "-S", "1", coverage,
+	})
+	stdOut, _, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Contains(t, stdOut, "≡ Saved history entry with label: ci")
+
+	// nothing is written to the working tree.
+	require.NoFileExists(t, HistoryFileFlagDefault)
+
+	cmd = setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", "git-branch://" + history.Branch, "--show-history", "--format", "json", "--no-color",
+	})
+	stdOut, stdErr, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Empty(t, stdErr)
+
+	var h history.History
+	require.NoError(t, json.Unmarshal([]byte(stdOut), &h))
+	require.Len(t, h.Entries, 1)
+	require.Equal(t, "ci", h.Entries[0].Label)
+}
+
+func Test_run_HistoryFileDirURI(t *testing.T) {
+	coverage := test.CreateTempCoverageFile(t, test.TestCoverageOut)
+	dir := filepath.Join(t.TempDir(), "history")
+
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{
+		"--history-file", "dir://" + dir, "--save-history", "--label", "ci", "-w",
+		"-s", "50", "-b", "50", "-B", "2", "-S", "1", coverage,
+	})
+	stdOut, _, err := runCmdForTest(t, cmd)
+	require.NoError(t, err)
+	require.Contains(t, stdOut, "≡ Saved history entry with label: ci")
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	cmd = setupTestCmd()
+	cmd.SetArgs([]string{"--history-file", "dir://" + dir, "--show-history", "--format", "json", "--no-color"})
+	stdOut, _, err = runCmdForTest(t, cmd)
+	require.NoError(t, err)
+
+	var h history.History
+	require.NoError(t, json.Unmarshal([]byte(stdOut), &h))
+	require.Len(t, h.Entries, 1)
+	require.Equal(t, "ci", h.Entries[0].Label)
+}
+
+func Test_run_HistoryFileUnsupportedURI(t *testing.T) {
+	cmd := setupTestCmd()
+	cmd.SetArgs([]string{"--history-file", "s3://bucket/history.json", "--show-history"})
+	_, _, err := runCmdForTest(t, cmd)
+	require.ErrorContains(t, err, `unsupported history storage scheme "s3"`)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+func newSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:       "schema [" + strings.Join(schema.Names, "|") + "]",
+		Short:     "Print the JSON Schema for the " + config.AppName + " config file or structured outputs",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: schema.Names,
+		RunE: func(_ *cobra.Command, args []string) error {
+			s, err := schema.ByName(args[0])
+			if err != nil {
+				return err
+			}
+			b, err := schema.Marshal(s)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(b))
+			return nil
+		},
+		SilenceUsage: true,
+	}
+}
@@ -87,12 +87,12 @@ func collect(profiles []*cover.Profile, cfg *config.Config) (Results, bool) { //
 				LinePercentage:      linePct,
 				LineThreshold:       lineThreshold,
 				Failed:              failed,
-				stmtHits:            stmtHits,
-				blockHits:           blockHits,
-				lineHits:            lineHits,
-				stmts:               stmts,
-				blocks:              blocks,
-				lines:               linesCount,
+				StatementsCovered:   stmtHits,
+				StatementsTotal:     stmts,
+				BlocksCovered:       blockHits,
+				BlocksTotal:         blocks,
+				LinesCovered:        lineHits,
+				LinesTotal:          linesCount,
 			},
 		}
 
@@ -102,12 +102,12 @@ func collect(profiles []*cover.Profile, cfg *config.Config) (Results, bool) { //
 
 		results.ByFile = append(results.ByFile, byFile)
 
-		results.ByTotal.Statements.totalStatements += stmts
-		results.ByTotal.Statements.totalCoveredStatements += stmtHits
-		results.ByTotal.Blocks.totalBlocks += blocks
-		results.ByTotal.Blocks.totalCoveredBlocks += blockHits
-		results.ByTotal.Lines.totalLines += linesCount
-		results.ByTotal.Lines.totalCoveredLines += lineHits
+		results.ByTotal.Statements.Total += stmts
+		results.ByTotal.Statements.Covered += stmtHits
+		results.ByTotal.Blocks.Total += blocks
+		results.ByTotal.Blocks.Covered += blockHits
+		results.ByTotal.Lines.Total += linesCount
+		results.ByTotal.Lines.Covered += lineHits
 	}
 
 	sortFileResults(results.ByFile, cfg)
@@ -130,23 +130,23 @@ func collectPackageResults(results *Results, cfg *config.Config) bool {
 		if w, exists := working[path.Dir(v.File)]; exists {
 			p = w
 		}
-		p.stmtHits += v.stmtHits
-		p.blockHits += v.blockHits
-		p.lineHits += v.lineHits
-		p.blocks += v.blocks
-		p.stmts += v.stmts
-		p.lines += v.lines
+		p.StatementsCovered += v.StatementsCovered
+		p.BlocksCovered += v.BlocksCovered
+		p.LinesCovered += v.LinesCovered
+		p.BlocksTotal += v.BlocksTotal
+		p.StatementsTotal += v.StatementsTotal
+		p.LinesTotal += v.LinesTotal
 		working[path.Dir(v.File)] = p
 	}
 
 	hasFailed := false
 	for _, v := range working {
-		v.Statements = fmt.Sprintf("%d/%d", v.stmtHits, v.stmts)
-		v.Blocks = fmt.Sprintf("%d/%d", v.blockHits, v.blocks)
-		v.Lines = fmt.Sprintf("%d/%d", v.lineHits, v.lines)
-		v.StatementPercentage = math.Percent(v.stmtHits, v.stmts)
-		v.BlockPercentage = math.Percent(v.blockHits, v.blocks)
-		v.LinePercentage = math.Percent(v.lineHits, v.lines)
+		v.Statements = fmt.Sprintf("%d/%d", v.StatementsCovered, v.StatementsTotal)
+		v.Blocks = fmt.Sprintf("%d/%d", v.BlocksCovered, v.BlocksTotal)
+		v.Lines = fmt.Sprintf("%d/%d", v.LinesCovered, v.LinesTotal)
+		v.StatementPercentage = math.Percent(v.StatementsCovered, v.StatementsTotal)
+		v.BlockPercentage = math.Percent(v.BlocksCovered, v.BlocksTotal)
+		v.LinePercentage = math.Percent(v.LinesCovered, v.LinesTotal)
 
 		v.StatementThreshold = cfg.StatementThreshold
 		if t, ok := cfg.PerPackage.Statements[v.Package]; ok {
@@ -177,26 +177,26 @@ func collectPackageResults(results *Results, cfg *config.Config) bool {
 func setTotals(results *Results, cfg *config.Config) {
 	results.ByTotal.Statements.Threshold = cfg.Total[config.StatementsSection]
 	results.ByTotal.Statements.Coverage =
-		fmt.Sprintf("%d/%d", results.ByTotal.Statements.totalCoveredStatements,
-			results.ByTotal.Statements.totalStatements)
-	results.ByTotal.Statements.Percentage = math.Percent(results.ByTotal.Statements.totalCoveredStatements,
-		results.ByTotal.Statements.totalStatements)
+		fmt.Sprintf("%d/%d", results.ByTotal.Statements.Covered,
+			results.ByTotal.Statements.Total)
+	results.ByTotal.Statements.Percentage = math.Percent(results.ByTotal.Statements.Covered,
+		results.ByTotal.Statements.Total)
 	results.ByTotal.Statements.Failed = results.ByTotal.Statements.Percentage < results.ByTotal.Statements.Threshold
 
 	results.ByTotal.Blocks.Threshold = cfg.Total[config.BlocksSection]
 	results.ByTotal.Blocks.Coverage =
-		fmt.Sprintf("%d/%d", results.ByTotal.Blocks.totalCoveredBlocks,
-			results.ByTotal.Blocks.totalBlocks)
-	results.ByTotal.Blocks.Percentage = math.Percent(results.ByTotal.Blocks.totalCoveredBlocks,
-		results.ByTotal.Blocks.totalBlocks)
+		fmt.Sprintf("%d/%d", results.ByTotal.Blocks.Covered,
+			results.ByTotal.Blocks.Total)
+	results.ByTotal.Blocks.Percentage = math.Percent(results.ByTotal.Blocks.Covered,
+		results.ByTotal.Blocks.Total)
 	results.ByTotal.Blocks.Failed = results.ByTotal.Blocks.Percentage < results.ByTotal.Blocks.Threshold
 
 	results.ByTotal.Lines.Threshold = cfg.Total[config.LinesSection]
 	results.ByTotal.Lines.Coverage =
-		fmt.Sprintf("%d/%d", results.ByTotal.Lines.totalCoveredLines,
-			results.ByTotal.Lines.totalLines)
-	results.ByTotal.Lines.Percentage = math.Percent(results.ByTotal.Lines.totalCoveredLines,
-		results.ByTotal.Lines.totalLines)
+		fmt.Sprintf("%d/%d", results.ByTotal.Lines.Covered,
+			results.ByTotal.Lines.Total)
+	results.ByTotal.Lines.Percentage = math.Percent(results.ByTotal.Lines.Covered,
+		results.ByTotal.Lines.Total)
 	results.ByTotal.Lines.Failed = results.ByTotal.Lines.Percentage < results.ByTotal.Lines.Threshold
 }
 
@@ -211,11 +211,11 @@ func sortKey(by *By, sortBy string) (float64, bool) {
 	case config.SortByLinePercent:
 		return by.LinePercentage, true
 	case config.SortByStatements:
-		return float64(by.stmtHits), true
+		return float64(by.StatementsCovered), true
 	case config.SortByBlocks:
-		return float64(by.blockHits), true
+		return float64(by.BlocksCovered), true
 	case config.SortByLines:
-		return float64(by.lineHits), true
+		return float64(by.LinesCovered), true
 	default:
 		return 0, false
 	}
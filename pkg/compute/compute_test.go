@@ -262,9 +262,9 @@ func testFileSorting(t *testing.T, sortBy string, results []ByFile, expectedAsc,
 
 func TestSortResults_ByStatementPercent(t *testing.T) {
 	results := []ByFile{
-		{File: "high.go", By: By{StatementPercentage: 90.0, stmtHits: 9}},
-		{File: "low.go", By: By{StatementPercentage: 10.0, stmtHits: 1}},
-		{File: "medium.go", By: By{StatementPercentage: 50.0, stmtHits: 5}},
+		{File: "high.go", By: By{StatementPercentage: 90.0, StatementsCovered: 9}},
+		{File: "low.go", By: By{StatementPercentage: 10.0, StatementsCovered: 1}},
+		{File: "medium.go", By: By{StatementPercentage: 50.0, StatementsCovered: 5}},
 	}
 
 	testFileSorting(t, config.SortByStatementPercent, results,
@@ -274,9 +274,9 @@ func TestSortResults_ByStatementPercent(t *testing.T) {
 
 func TestSortResults_ByBlockPercent(t *testing.T) {
 	results := []ByFile{
-		{File: "high.go", By: By{BlockPercentage: 90.0, blockHits: 9}},
-		{File: "low.go", By: By{BlockPercentage: 10.0, blockHits: 1}},
-		{File: "medium.go", By: By{BlockPercentage: 50.0, blockHits: 5}},
+		{File: "high.go", By: By{BlockPercentage: 90.0, BlocksCovered: 9}},
+		{File: "low.go", By: By{BlockPercentage: 10.0, BlocksCovered: 1}},
+		{File: "medium.go", By: By{BlockPercentage: 50.0, BlocksCovered: 5}},
 	}
 
 	testFileSorting(t, config.SortByBlockPercent, results,
@@ -286,9 +286,9 @@ func TestSortResults_ByBlockPercent(t *testing.T) {
 
 func TestSortResults_ByStatements(t *testing.T) {
 	results := []ByFile{
-		{File: "high.go", By: By{stmtHits: 90}},
-		{File: "low.go", By: By{stmtHits: 10}},
-		{File: "medium.go", By: By{stmtHits: 50}},
+		{File: "high.go", By: By{StatementsCovered: 90}},
+		{File: "low.go", By: By{StatementsCovered: 10}},
+		{File: "medium.go", By: By{StatementsCovered: 50}},
 	}
 
 	testFileSorting(t, config.SortByStatements, results,
@@ -298,9 +298,9 @@ func TestSortResults_ByStatements(t *testing.T) {
 
 func TestSortResults_ByBlocks(t *testing.T) {
 	results := []ByFile{
-		{File: "high.go", By: By{blockHits: 90}},
-		{File: "low.go", By: By{blockHits: 10}},
-		{File: "medium.go", By: By{blockHits: 50}},
+		{File: "high.go", By: By{BlocksCovered: 90}},
+		{File: "low.go", By: By{BlocksCovered: 10}},
+		{File: "medium.go", By: By{BlocksCovered: 50}},
 	}
 
 	testFileSorting(t, config.SortByBlocks, results,
@@ -329,9 +329,9 @@ func testPackageSorting(t *testing.T, sortBy string, results []ByPackage, expect
 
 func TestSortPackageResults_ByStatementPercent(t *testing.T) {
 	results := []ByPackage{
-		{Package: "pkg/high", By: By{StatementPercentage: 90.0, stmtHits: 9}},
-		{Package: "pkg/low", By: By{StatementPercentage: 10.0, stmtHits: 1}},
-		{Package: "pkg/medium", By: By{StatementPercentage: 50.0, stmtHits: 5}},
+		{Package: "pkg/high", By: By{StatementPercentage: 90.0, StatementsCovered: 9}},
+		{Package: "pkg/low", By: By{StatementPercentage: 10.0, StatementsCovered: 1}},
+		{Package: "pkg/medium", By: By{StatementPercentage: 50.0, StatementsCovered: 5}},
 	}
 
 	testPackageSorting(t, config.SortByStatementPercent, results,
@@ -1,5 +1,10 @@
 package compute
 
+import (
+	"strconv"
+	"strings"
+)
+
 // HasBy required interface for all descendants of By.
 type HasBy interface {
 	GetBy() By
@@ -7,19 +12,23 @@ type HasBy interface {
 
 // By holds cover.Profile information.
 type By struct {
-	Statements                    string  `json:"statementCoverage"   yaml:"statementCoverage"`
-	Blocks                        string  `json:"blockCoverage"       yaml:"blockCoverage"`
-	Lines                         string  `json:"lineCoverage"        yaml:"lineCoverage"`
-	StatementPercentage           float64 `json:"statementPercentage" yaml:"statementPercentage"`
-	BlockPercentage               float64 `json:"blockPercentage"     yaml:"blockPercentage"`
-	LinePercentage                float64 `json:"linePercentage"      yaml:"linePercentage"`
-	StatementThreshold            float64 `json:"statementThreshold"  yaml:"statementThreshold"`
-	BlockThreshold                float64 `json:"blockThreshold"      yaml:"blockThreshold"`
-	LineThreshold                 float64 `json:"lineThreshold"       yaml:"lineThreshold"`
-	Failed                        bool    `json:"failed"              yaml:"failed"`
-	UncoveredLines                string  `json:"uncoveredLines,omitempty" yaml:"uncoveredLines,omitempty"`
-	stmts, blocks, lines          int
-	stmtHits, blockHits, lineHits int
+	Statements          string  `json:"statementCoverage"   yaml:"statementCoverage"`
+	Blocks              string  `json:"blockCoverage"       yaml:"blockCoverage"`
+	Lines               string  `json:"lineCoverage"        yaml:"lineCoverage"`
+	StatementsCovered   int     `json:"statementsCovered"   yaml:"statementsCovered"`
+	StatementsTotal     int     `json:"statementsTotal"     yaml:"statementsTotal"`
+	BlocksCovered       int     `json:"blocksCovered"       yaml:"blocksCovered"`
+	BlocksTotal         int     `json:"blocksTotal"         yaml:"blocksTotal"`
+	LinesCovered        int     `json:"linesCovered"        yaml:"linesCovered"`
+	LinesTotal          int     `json:"linesTotal"          yaml:"linesTotal"`
+	StatementPercentage float64 `json:"statementPercentage" yaml:"statementPercentage"`
+	BlockPercentage     float64 `json:"blockPercentage"     yaml:"blockPercentage"`
+	LinePercentage      float64 `json:"linePercentage"      yaml:"linePercentage"`
+	StatementThreshold  float64 `json:"statementThreshold"  yaml:"statementThreshold"`
+	BlockThreshold      float64 `json:"blockThreshold"      yaml:"blockThreshold"`
+	LineThreshold       float64 `json:"lineThreshold"       yaml:"lineThreshold"`
+	Failed              bool    `json:"failed"              yaml:"failed"`
+	UncoveredLines      string  `json:"uncoveredLines,omitempty" yaml:"uncoveredLines,omitempty"`
 }
 
 // ByFile holds information for a cover.Profile result of a file.
@@ -53,32 +62,32 @@ type Totals struct {
 
 // TotalLines holds cover.Profile total line results.
 type TotalLines struct {
-	totalLines        int
-	totalCoveredLines int
-	Coverage          string  `json:"coverage"   yaml:"coverage"`
-	Threshold         float64 `json:"threshold"  yaml:"threshold"`
-	Percentage        float64 `json:"percentage" yaml:"percentage"`
-	Failed            bool    `json:"failed"     yaml:"failed"`
+	Coverage   string  `json:"coverage"   yaml:"coverage"`
+	Covered    int     `json:"covered"    yaml:"covered"`
+	Total      int     `json:"total"      yaml:"total"`
+	Threshold  float64 `json:"threshold"  yaml:"threshold"`
+	Percentage float64 `json:"percentage" yaml:"percentage"`
+	Failed     bool    `json:"failed"     yaml:"failed"`
 }
 
 // TotalBlocks holds cover.Profile total block results.
 type TotalBlocks struct {
-	totalBlocks        int
-	totalCoveredBlocks int
-	Coverage           string  `json:"coverage"   yaml:"coverage"`
-	Threshold          float64 `json:"threshold"  yaml:"threshold"`
-	Percentage         float64 `json:"percentage" yaml:"percentage"`
-	Failed             bool    `json:"failed"     yaml:"failed"`
+	Coverage   string  `json:"coverage"   yaml:"coverage"`
+	Covered    int     `json:"covered"    yaml:"covered"`
+	Total      int     `json:"total"      yaml:"total"`
+	Threshold  float64 `json:"threshold"  yaml:"threshold"`
+	Percentage float64 `json:"percentage" yaml:"percentage"`
+	Failed     bool    `json:"failed"     yaml:"failed"`
 }
 
 // TotalStatements holds cover.Profile total statement results.
 type TotalStatements struct {
-	Coverage               string  `json:"coverage"   yaml:"coverage"`
-	Threshold              float64 `json:"threshold"  yaml:"threshold"`
-	Percentage             float64 `json:"percentage" yaml:"percentage"`
-	Failed                 bool    `json:"failed"     yaml:"failed"`
-	totalCoveredStatements int
-	totalStatements        int
+	Coverage   string  `json:"coverage"   yaml:"coverage"`
+	Covered    int     `json:"covered"    yaml:"covered"`
+	Total      int     `json:"total"      yaml:"total"`
+	Threshold  float64 `json:"threshold"  yaml:"threshold"`
+	Percentage float64 `json:"percentage" yaml:"percentage"`
+	Failed     bool    `json:"failed"     yaml:"failed"`
 }
 
 // Results holds information for all stats collected form the cover.Profile data.
@@ -87,3 +96,22 @@ type Results struct {
 	ByPackage []ByPackage `json:"byPackage" yaml:"byPackage"`
 	ByTotal   Totals      `json:"byTotal"   yaml:"byTotal"`
 }
+
+// ParseCoverage parses a "covered/total" coverage fraction, such as the
+// Statements of a By, into its counts. It reports false when coverage is not
+// such a fraction.
+func ParseCoverage(coverage string) (int, int, bool) {
+	c, t, ok := strings.Cut(coverage, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	covered, err := strconv.Atoi(c)
+	if err != nil {
+		return 0, 0, false
+	}
+	total, err := strconv.Atoi(t)
+	if err != nil {
+		return 0, 0, false
+	}
+	return covered, total, true
+}
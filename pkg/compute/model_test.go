@@ -18,6 +18,12 @@ const (
       "statementCoverage": "150/150",
       "blockCoverage": "1/1",
       "lineCoverage": "11/11",
+      "statementsCovered": 150,
+      "statementsTotal": 150,
+      "blocksCovered": 1,
+      "blocksTotal": 1,
+      "linesCovered": 11,
+      "linesTotal": 11,
       "statementPercentage": 100,
       "blockPercentage": 100,
       "linePercentage": 100,
@@ -33,6 +39,12 @@ const (
       "statementCoverage": "150/150",
       "blockCoverage": "1/1",
       "lineCoverage": "11/11",
+      "statementsCovered": 150,
+      "statementsTotal": 150,
+      "blocksCovered": 1,
+      "blocksTotal": 1,
+      "linesCovered": 11,
+      "linesTotal": 11,
       "statementPercentage": 100,
       "blockPercentage": 100,
       "linePercentage": 100,
@@ -46,18 +58,24 @@ const (
   "byTotal": {
     "statements": {
       "coverage": "150/150",
+      "covered": 150,
+      "total": 150,
       "threshold": 0,
       "percentage": 100,
       "failed": false
     },
     "blocks": {
       "coverage": "1/1",
+      "covered": 1,
+      "total": 1,
       "threshold": 0,
       "percentage": 100,
       "failed": false
     },
     "lines": {
       "coverage": "11/11",
+      "covered": 11,
+      "total": 11,
       "threshold": 0,
       "percentage": 100,
       "failed": false
@@ -69,6 +87,12 @@ const (
     - statementCoverage: 150/150
       blockCoverage: 1/1
       lineCoverage: 11/11
+      statementsCovered: 150
+      statementsTotal: 150
+      blocksCovered: 1
+      blocksTotal: 1
+      linesCovered: 11
+      linesTotal: 11
       statementPercentage: 100
       blockPercentage: 100
       linePercentage: 100
@@ -81,6 +105,12 @@ byPackage:
     - statementCoverage: 150/150
       blockCoverage: 1/1
       lineCoverage: 11/11
+      statementsCovered: 150
+      statementsTotal: 150
+      blocksCovered: 1
+      blocksTotal: 1
+      linesCovered: 11
+      linesTotal: 11
       statementPercentage: 100
       blockPercentage: 100
       linePercentage: 100
@@ -92,16 +122,22 @@ byPackage:
 byTotal:
     statements:
         coverage: 150/150
+        covered: 150
+        total: 150
         threshold: 0
         percentage: 100
         failed: false
     blocks:
         coverage: 1/1
+        covered: 1
+        total: 1
         threshold: 0
         percentage: 100
         failed: false
     lines:
         coverage: 11/11
+        covered: 11
+        total: 11
         threshold: 0
         percentage: 100
         failed: false
@@ -177,3 +213,15 @@ func TestModelMarshalJson(t *testing.T) {
 	require.NotEmpty(t, out)
 	require.JSONEq(t, expectJSON, string(out))
 }
+
+func TestParseCoverage(t *testing.T) {
+	covered, total, ok := compute.ParseCoverage("180/648")
+	require.True(t, ok)
+	require.Equal(t, 180, covered)
+	require.Equal(t, 648, total)
+
+	for _, coverage := range []string{"", "180", "a/648", "180/b"} {
+		_, _, ok = compute.ParseCoverage(coverage)
+		require.False(t, ok, coverage)
+	}
+}
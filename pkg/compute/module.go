@@ -111,10 +111,12 @@ func findModuleName(profiles []*cover.Profile, cfg *config.Config) string {
 // downstream reporting (tables, JSON/YAML, --inspect headers) uses the same
 // short, module-relative paths. CollectResults no longer normalizes names
 // itself; call NormalizeNames exactly once per profile set before handing
-// the profiles to CollectResults or the --inspect path.
-func NormalizeNames(profiles []*cover.Profile, cfg *config.Config) {
+// the profiles to CollectResults or the --inspect path. The module prefix that
+// was stripped is returned without its trailing slash.
+func NormalizeNames(profiles []*cover.Profile, cfg *config.Config) string {
 	moduleName := findModuleName(profiles, cfg)
 	for _, profile := range profiles {
 		profile.FileName = strings.Replace(profile.FileName, moduleName, "", 1)
 	}
+	return strings.TrimSuffix(moduleName, "/")
 }
@@ -34,14 +34,16 @@ const (
 	SortOrderDesc    = "desc"
 	SortOrderDefault = SortOrderAsc
 
-	FormatJSON    = "json"
-	FormatYAML    = "yaml"
-	FormatTable   = "table"
-	FormatCSV     = "csv"
-	FormatHTML    = "html"
-	FormatTSV     = "tsv"
-	FormatMD      = "md"
-	FormatDefault = FormatTable
+	FormatJSON      = "json"
+	FormatYAML      = "yaml"
+	FormatTable     = "table"
+	FormatCSV       = "csv"
+	FormatHTML      = "html"
+	FormatTSV       = "tsv"
+	FormatMD        = "md"
+	FormatDashboard = "dashboard"
+	FormatNDJSON    = "ndjson"
+	FormatDefault   = FormatTable
 
 	TableStyleDefault  = "default"
 	TableStyleLight    = "light"
@@ -65,6 +67,10 @@ const (
 	LineThresholdOff     = thresholdOff
 	LineThresholdMax     = thresholdMax
 
+	NewFileThresholdDefault = thresholdOff
+	NewFileThresholdOff     = thresholdOff
+	NewFileThresholdMax     = thresholdMax
+
 	// InspectContextDefault is the default number of context lines shown around
 	// uncovered blocks by --inspect.
 	InspectContextDefault = 2
@@ -90,9 +96,9 @@ type PerOverride map[string]float64
 
 // PerThresholdOverride holds PerOverride's for Statements, Blocks, and Lines.
 type PerThresholdOverride struct {
-	Statements PerOverride `yaml:"statements"`
-	Blocks     PerOverride `yaml:"blocks"`
-	Lines      PerOverride `yaml:"lines"`
+	Statements PerOverride `json:"statements" yaml:"statements"`
+	Blocks     PerOverride `json:"blocks"     yaml:"blocks"`
+	Lines      PerOverride `json:"lines"      yaml:"lines"`
 }
 
 // Config for application.
@@ -117,9 +123,14 @@ type Config struct {
 	NoUncoveredLines   bool                 `yaml:"noUncoveredLines,omitempty"`
 	InspectContext     int                  `yaml:"inspectContext,omitempty"`
 	SyntaxStyle        string               `yaml:"syntaxStyle,omitempty"`
+	Envelope           bool                 `yaml:"envelope,omitempty"`
+	NewFileThreshold   float64              `yaml:"newFileThreshold,omitempty"`
+	Regression         Regression           `yaml:"regression,omitempty"`
+	Retention          Retention            `yaml:"retention,omitempty"`
 	// not configurable via YAML
 	InspectFiles []string `yaml:"-"`
 	Inspect      bool     `yaml:"-"`
+	ConfigPath   string   `yaml:"-"`
 }
 
 // Load a Config from a path or produce an error.
@@ -139,6 +150,7 @@ func Load(path string) (*Config, error) {
 		return nil, err
 	}
 
+	cfg.ConfigPath = path
 	return cfg, nil
 }
 
@@ -172,6 +184,15 @@ func (c *Config) Validate() error { //nolint:cyclop
 	if c.LineThreshold < LineThresholdOff || c.LineThreshold > LineThresholdMax {
 		return errors.New("line threshold must be between 0 and 100")
 	}
+	if c.NewFileThreshold < NewFileThresholdOff || c.NewFileThreshold > NewFileThresholdMax {
+		return errors.New("new file threshold must be between 0 and 100")
+	}
+	if err := c.Regression.validate(); err != nil {
+		return err
+	}
+	if err := c.Retention.validate(); err != nil {
+		return err
+	}
 	if c.InspectContext < 0 {
 		return errors.New("inspect-context must be greater than or equal to 0")
 	}
@@ -201,11 +222,13 @@ func (c *Config) Validate() error { //nolint:cyclop
 	}
 
 	switch c.Format {
-	case FormatJSON, FormatYAML, FormatTable, FormatMD, FormatCSV, FormatHTML, FormatTSV:
+	case FormatJSON, FormatYAML, FormatTable, FormatMD, FormatCSV, FormatHTML, FormatTSV, FormatDashboard,
+		FormatNDJSON:
 		break
 	default:
-		return fmt.Errorf("format must be one of %s|%s|%s|%s|%s|%s|%s",
-			FormatJSON, FormatYAML, FormatTable, FormatCSV, FormatHTML, FormatTSV, FormatMD)
+		return fmt.Errorf("format must be one of %s|%s|%s|%s|%s|%s|%s|%s|%s",
+			FormatJSON, FormatYAML, FormatTable, FormatCSV, FormatHTML, FormatTSV, FormatMD, FormatDashboard,
+			FormatNDJSON)
 	}
 
 	switch c.TableStyle {
@@ -216,7 +239,7 @@ func (c *Config) Validate() error { //nolint:cyclop
 			TableStyleDefault, TableStyleLight, TableStyleBold, TableStyleRounded, TableStyleDouble)
 	}
 
-	if c.NoSummary && c.NoTable && c.Format != FormatJSON && c.Format != FormatYAML {
+	if c.NoSummary && c.NoTable && !c.IsDocumentFormat() {
 		return fmt.Errorf("cannot specify both no-summary and no-table with format %s", c.Format)
 	}
 
@@ -231,6 +254,19 @@ func (c *Config) Validate() error { //nolint:cyclop
 	return nil
 }
 
+// IsDocumentFormat reports whether the configured format writes machine
+// readable output to stdout (json, yaml, ndjson, or the html dashboard) which
+// informational messages would corrupt. The table and summary flags do not
+// apply to these.
+func (c *Config) IsDocumentFormat() bool {
+	switch c.Format {
+	case FormatJSON, FormatYAML, FormatDashboard, FormatNDJSON:
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *Config) initPerFileWhenNil() {
 	if c.PerFile.Blocks == nil {
 		c.PerFile.Blocks = PerOverride{}
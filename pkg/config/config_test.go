@@ -226,3 +226,38 @@ func TestValidate_SyntaxStyle(t *testing.T) {
 		require.Contains(t, err.Error(), "syntax-style")
 	})
 }
+
+func TestValidate_NewFileThreshold(t *testing.T) {
+	for _, v := range []float64{config.NewFileThresholdOff, 80, config.NewFileThresholdMax} {
+		cfg := &config.Config{}
+		cfg.ApplyDefaults()
+		cfg.NewFileThreshold = v
+		require.NoError(t, cfg.Validate())
+	}
+
+	for _, v := range []float64{-1, 101} {
+		cfg := &config.Config{}
+		cfg.ApplyDefaults()
+		cfg.NewFileThreshold = v
+		require.ErrorContains(t, cfg.Validate(), "new file threshold must be between 0 and 100")
+	}
+}
+
+func TestConfig_IsDocumentFormat(t *testing.T) {
+	tests := map[string]bool{
+		config.FormatJSON:      true,
+		config.FormatYAML:      true,
+		config.FormatDashboard: true,
+		config.FormatTable:     false,
+		config.FormatMD:        false,
+		config.FormatHTML:      false,
+		config.FormatCSV:       false,
+		config.FormatTSV:       false,
+	}
+	for format, want := range tests {
+		t.Run(format, func(t *testing.T) {
+			cfg := &config.Config{Format: format}
+			require.Equal(t, want, cfg.IsDocumentFormat())
+		})
+	}
+}
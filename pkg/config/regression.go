@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Regression scopes.
+const (
+	RegressionScopeFile    = "file"
+	RegressionScopePackage = "package"
+	RegressionScopeTotal   = "total"
+)
+
+// Regression holds the settings for failing on coverage regressions against a
+// history entry.
+type Regression struct {
+	// Fail enables regression gating.
+	Fail bool `yaml:"fail,omitempty"`
+	// Baseline is the branch whose latest history entry is compared against
+	// when no explicit compare ref is given.
+	Baseline string `yaml:"baseline,omitempty"`
+	// Tolerance is the allowed coverage drop per scope and metric.
+	Tolerance RegressionTolerance `yaml:"tolerance,omitempty"`
+}
+
+// RegressionTolerance holds the allowed coverage drop, in percentage points,
+// per metric for each scope. Metrics without a tolerance allow no drop.
+type RegressionTolerance struct {
+	File    PerOverride `yaml:"file,omitempty"`
+	Package PerOverride `yaml:"package,omitempty"`
+	Total   PerOverride `yaml:"total,omitempty"`
+}
+
+// For returns the tolerance of metric in scope.
+func (t RegressionTolerance) For(scope, metric string) float64 {
+	switch scope {
+	case RegressionScopeFile:
+		return t.File[metric]
+	case RegressionScopePackage:
+		return t.Package[metric]
+	case RegressionScopeTotal:
+		return t.Total[metric]
+	default:
+		return 0
+	}
+}
+
+// Set parses spec as scope[.metric]=value and sets the tolerance. Without a
+// metric the value applies to all metrics of the scope.
+func (t *RegressionTolerance) Set(spec string) error {
+	key, raw, ok := strings.Cut(spec, "=")
+	if !ok {
+		return fmt.Errorf("regression tolerance %q must be scope[.metric]=value", spec)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return fmt.Errorf("regression tolerance %q has an invalid value: %w", spec, err)
+	}
+
+	scope, metric, hasMetric := strings.Cut(strings.TrimSpace(key), ".")
+	target := t.scope(scope)
+	if target == nil {
+		return fmt.Errorf("regression tolerance %q scope must be one of %s|%s|%s",
+			spec, RegressionScopeFile, RegressionScopePackage, RegressionScopeTotal)
+	}
+
+	metrics := []string{StatementsSection, BlocksSection, LinesSection}
+	if hasMetric {
+		if !isSection(metric) {
+			return fmt.Errorf("regression tolerance %q metric must be one of %s|%s|%s",
+				spec, StatementsSection, BlocksSection, LinesSection)
+		}
+		metrics = []string{metric}
+	}
+	for _, m := range metrics {
+		(*target)[m] = value
+	}
+	return nil
+}
+
+// scope returns the PerOverride of scope, allocating it when nil, or nil for
+// an unknown scope.
+func (t *RegressionTolerance) scope(scope string) *PerOverride {
+	var target *PerOverride
+	switch scope {
+	case RegressionScopeFile:
+		target = &t.File
+	case RegressionScopePackage:
+		target = &t.Package
+	case RegressionScopeTotal:
+		target = &t.Total
+	default:
+		return nil
+	}
+	if *target == nil {
+		*target = PerOverride{}
+	}
+	return target
+}
+
+func (r *Regression) validate() error {
+	for scope, tolerances := range map[string]PerOverride{
+		RegressionScopeFile:    r.Tolerance.File,
+		RegressionScopePackage: r.Tolerance.Package,
+		RegressionScopeTotal:   r.Tolerance.Total,
+	} {
+		for metric, v := range tolerances {
+			if !isSection(metric) {
+				return fmt.Errorf("regression tolerance %s.%s metric must be one of %s|%s|%s",
+					scope, metric, StatementsSection, BlocksSection, LinesSection)
+			}
+			if v < thresholdOff || v > thresholdMax {
+				return errors.New("regression tolerance must be between 0 and 100")
+			}
+		}
+	}
+	return nil
+}
+
+func isSection(s string) bool {
+	return s == StatementsSection || s == BlocksSection || s == LinesSection
+}
@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegressionTolerance_Set(t *testing.T) {
+	var tol config.RegressionTolerance
+	require.NoError(t, tol.Set("file=1.5"))
+	require.NoError(t, tol.Set("file.lines=3"))
+	require.NoError(t, tol.Set("total.statements = 0.5"))
+
+	require.Equal(t, config.PerOverride{
+		config.StatementsSection: 1.5,
+		config.BlocksSection:     1.5,
+		config.LinesSection:      3,
+	}, tol.File)
+	require.Nil(t, tol.Package)
+	require.InDelta(t, 0.5, tol.For(config.RegressionScopeTotal, config.StatementsSection), 0)
+	require.Zero(t, tol.For(config.RegressionScopeTotal, config.BlocksSection))
+	require.Zero(t, tol.For(config.RegressionScopePackage, config.BlocksSection))
+	require.Zero(t, tol.For("bogus", config.BlocksSection))
+}
+
+func TestRegressionTolerance_Set_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"file":             "must be scope[.metric]=value",
+		"file=abc":         "invalid value",
+		"module=1":         "scope must be one of file|package|total",
+		"package.branch=1": "metric must be one of statements|blocks|lines",
+	}
+	for spec, msg := range tests {
+		t.Run(spec, func(t *testing.T) {
+			var tol config.RegressionTolerance
+			require.ErrorContains(t, tol.Set(spec), msg)
+		})
+	}
+}
+
+func TestValidate_RegressionTolerance(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Regression.Tolerance.Package = config.PerOverride{config.BlocksSection: 101}
+	require.ErrorContains(t, cfg.Validate(), "regression tolerance must be between 0 and 100")
+
+	cfg.Regression.Tolerance.Package = config.PerOverride{"branches": 1}
+	require.ErrorContains(t, cfg.Validate(), "regression tolerance package.branches metric must be one of")
+
+	cfg.Regression.Tolerance.Package = config.PerOverride{config.BlocksSection: 1}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestLoad_Regression(t *testing.T) {
+	tmpFile := path.Join(t.TempDir(), "test_config_regression.yaml")
+	err := os.WriteFile(tmpFile, []byte(`
+regression:
+  fail: true
+  baseline: main
+  tolerance:
+    total:
+      statements: 0.5
+`), 0600)
+	require.NoError(t, err)
+
+	cfg, err := config.Load(tmpFile)
+	require.NoError(t, err)
+	require.True(t, cfg.Regression.Fail)
+	require.Equal(t, "main", cfg.Regression.Baseline)
+	require.InDelta(t, 0.5, cfg.Regression.Tolerance.For(config.RegressionScopeTotal, config.StatementsSection), 0)
+}
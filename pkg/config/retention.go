@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention thinning periods.
+const (
+	ThinDay  = "day"
+	ThinWeek = "week"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// Retention holds the rules deciding which history entries are kept when
+// history is saved or pruned. Rules left unset keep every entry.
+type Retention struct {
+	// PerBranch keeps at most this many of the newest entries of each branch
+	// [0=no limit].
+	PerBranch int `yaml:"perBranch,omitempty"`
+	// KeepTagged always keeps entries of tagged commits.
+	KeepTagged bool `yaml:"keepTagged,omitempty"`
+	// KeepLabeled always keeps entries saved with a label.
+	KeepLabeled bool `yaml:"keepLabeled,omitempty"`
+	// MaxAge drops entries older than this age, such as 90d, 12w, or 720h.
+	MaxAge string `yaml:"maxAge,omitempty"`
+	// Thin keeps only the newest entry per day or week of each branch and
+	// label for entries older than ThinAfter.
+	Thin string `yaml:"thin,omitempty"`
+	// ThinAfter is the age from which entries are thinned [default 0, all].
+	ThinAfter string `yaml:"thinAfter,omitempty"`
+}
+
+// IsSet reports whether any rule removes entries.
+func (r Retention) IsSet() bool {
+	return r.PerBranch > 0 || r.MaxAge != "" || r.Thin != ""
+}
+
+// MaxAgeDuration returns MaxAge as a duration, or 0 when unset.
+func (r Retention) MaxAgeDuration() (time.Duration, error) {
+	return ParseAge(r.MaxAge)
+}
+
+// ThinAfterDuration returns ThinAfter as a duration, or 0 when unset.
+func (r Retention) ThinAfterDuration() (time.Duration, error) {
+	return ParseAge(r.ThinAfter)
+}
+
+// ParseAge parses an age given as a number of days such as 90d, a number of
+// weeks such as 12w, or a go duration such as 720h. An empty age is 0.
+func ParseAge(age string) (time.Duration, error) {
+	age = strings.TrimSpace(age)
+	if age == "" {
+		return 0, nil
+	}
+
+	units := map[byte]time.Duration{'d': day, 'w': week}
+	var (
+		d   time.Duration
+		err error
+	)
+	if unit, ok := units[age[len(age)-1]]; ok {
+		var n int
+		n, err = strconv.Atoi(age[:len(age)-1])
+		d = time.Duration(n) * unit
+	} else {
+		d, err = time.ParseDuration(age)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q, expected days (90d), weeks (12w), or a duration (720h)", age)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("age %q must not be negative", age)
+	}
+	return d, nil
+}
+
+func (r *Retention) validate() error {
+	if r.PerBranch < 0 {
+		return errors.New("retention perBranch must be greater than or equal to 0")
+	}
+	if _, err := r.MaxAgeDuration(); err != nil {
+		return fmt.Errorf("retention maxAge: %w", err)
+	}
+	if _, err := r.ThinAfterDuration(); err != nil {
+		return fmt.Errorf("retention thinAfter: %w", err)
+	}
+	switch r.Thin {
+	case "", ThinDay, ThinWeek:
+		return nil
+	default:
+		return fmt.Errorf("retention thin must be one of %s|%s", ThinDay, ThinWeek)
+	}
+}
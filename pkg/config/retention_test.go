@@ -0,0 +1,71 @@
+package config_test
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":     0,
+		"90d":  90 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"720h": 720 * time.Hour,
+	}
+	for age, want := range tests {
+		t.Run(age, func(t *testing.T) {
+			got, err := config.ParseAge(age)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+
+	_, err := config.ParseAge("soon")
+	require.ErrorContains(t, err, `invalid age "soon"`)
+	_, err = config.ParseAge("xd")
+	require.ErrorContains(t, err, `invalid age "xd"`)
+	_, err = config.ParseAge("-1d")
+	require.ErrorContains(t, err, `age "-1d" must not be negative`)
+}
+
+func TestValidate_Retention(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.ApplyDefaults()
+	require.False(t, cfg.Retention.IsSet())
+
+	cfg.Retention.PerBranch = -1
+	require.ErrorContains(t, cfg.Validate(), "retention perBranch must be greater than or equal to 0")
+
+	cfg.Retention = config.Retention{MaxAge: "a while"}
+	require.ErrorContains(t, cfg.Validate(), "retention maxAge: invalid age")
+
+	cfg.Retention = config.Retention{Thin: "month"}
+	require.ErrorContains(t, cfg.Validate(), "retention thin must be one of day|week")
+
+	cfg.Retention = config.Retention{Thin: config.ThinWeek, ThinAfter: "later"}
+	require.ErrorContains(t, cfg.Validate(), "retention thinAfter: invalid age")
+
+	cfg.Retention = config.Retention{Thin: config.ThinWeek, ThinAfter: "30d"}
+	require.NoError(t, cfg.Validate())
+	require.True(t, cfg.Retention.IsSet())
+}
+
+func TestLoad_Retention(t *testing.T) {
+	tmpFile := path.Join(t.TempDir(), "test_config_retention.yaml")
+	err := os.WriteFile(tmpFile, []byte(`
+retention:
+  perBranch: 10
+  keepTagged: true
+  maxAge: 90d
+`), 0600)
+	require.NoError(t, err)
+
+	cfg, err := config.Load(tmpFile)
+	require.NoError(t, err)
+	require.Equal(t, config.Retention{PerBranch: 10, KeepTagged: true, MaxAge: "90d"}, cfg.Retention)
+}
@@ -38,7 +38,7 @@ func GetChangedFiles(repoPath, targetRef string) (map[string]bool, error) {
 		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
 	}
 
-	targetHash, err := resolveReference(repo, targetRef)
+	targetHash, err := ResolveReference(repo, targetRef)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve target reference %q: %w", targetRef, err)
 	}
@@ -80,8 +80,10 @@ func addChangedFilesFromPatch(filePatch diff.FilePatch, changedFiles map[string]
 	}
 }
 
-// resolveReference resolves a git reference (branch, tag, commit) to a hash.
-func resolveReference(repo *git.Repository, ref string) (plumbing.Hash, error) {
+// ResolveReference resolves a git reference (branch, tag, commit, or revision
+// such as HEAD~1) to a commit hash. Annotated tags resolve to the commit they
+// point to.
+func ResolveReference(repo *git.Repository, ref string) (plumbing.Hash, error) {
 	// Try to resolve as a hash first
 	if len(ref) >= 7 && len(ref) <= 40 {
 		if hash, _ := plumbing.FromHex(ref); !hash.IsZero() {
@@ -99,6 +101,13 @@ func resolveReference(repo *git.Repository, ref string) (plumbing.Hash, error) {
 
 	// Try as a tag reference
 	if tagRef, err := repo.Reference(plumbing.NewTagReferenceName(ref), true); err == nil {
+		if tag, err := repo.TagObject(tagRef.Hash()); err == nil {
+			commit, err := tag.Commit()
+			if err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to get commit of tag %q: %w", ref, err)
+			}
+			return commit.Hash, nil
+		}
 		return tagRef.Hash(), nil
 	}
 
@@ -164,7 +164,25 @@ func TestResolveReference_InvalidRef(t *testing.T) {
 	require.NoError(t, err)
 
 	// Try to resolve a non-existent reference
-	_, err = resolveReference(repo, "nonexistent-branch")
+	_, err = ResolveReference(repo, "nonexistent-branch")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "could not resolve reference")
 }
+
+func TestResolveReference_AnnotatedTag(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+	commit, err := w.Commit("initial commit", &git.CommitOptions{AllowEmptyCommits: true, Author: signature})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.0.0", commit, &git.CreateTagOptions{Message: "release", Tagger: signature})
+	require.NoError(t, err)
+
+	hash, err := ResolveReference(repo, "v1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, commit, hash)
+}
@@ -0,0 +1,152 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+)
+
+// BestRef is the ref of a Comparison against the best results of a History,
+// see CompareBest.
+const BestRef = "best"
+
+// Best scopes select the entries whose best values results are compared
+// against, see ScopeBest.
+const (
+	// BestScopeLabelBranch keeps the entries of the label of the results
+	// recorded on their branch.
+	BestScopeLabelBranch = "label+branch"
+	// BestScopeLabel keeps the entries of the label of the results on any
+	// branch.
+	BestScopeLabel = "label"
+	// BestScopeAll keeps every entry.
+	BestScopeAll = "all"
+)
+
+// ScopeBest keeps the entries of the History that results recorded with label
+// on branch are compared against by CompareBest, so the results of one suite
+// are not held to the best of another. The scope is BestScopeLabelBranch, the
+// default, BestScopeLabel, or BestScopeAll. Under BestScopeLabelBranch entries
+// of every branch are kept when branch is empty or not a named branch, such as
+// "unknown" or "detached", see DetectGitInfo.
+func (h *History) ScopeBest(scope, label, branch string) error {
+	switch scope {
+	case "", BestScopeLabelBranch:
+		if branch == "unknown" || branch == "detached" {
+			branch = ""
+		}
+	case BestScopeLabel:
+		branch = ""
+	case BestScopeAll:
+		return nil
+	default:
+		return fmt.Errorf("invalid best scope %q, expected %s, %s, or %s",
+			scope, BestScopeLabelBranch, BestScopeLabel, BestScopeAll)
+	}
+	h.Filter(func(entry Entry) bool {
+		return entry.Label == label && (branch == "" || entry.Branch == branch)
+	})
+	return nil
+}
+
+// Best returns an Entry whose results hold, per file, package, and total, the
+// best value of each metric ever recorded in the History, being its
+// high-water mark. Each metric may come from a different entry, so only the
+// percentages of the results are meaningful. The Timestamp is that of the
+// newest entry. It returns nil when the History has no entries.
+func (h *History) Best() *Entry {
+	if len(h.Entries) == 0 {
+		return nil
+	}
+
+	best := &Entry{Timestamp: h.Entries[0].Timestamp}
+	files := map[string]int{}
+	packages := map[string]int{}
+	for _, entry := range h.Entries {
+		if entry.Timestamp.After(best.Timestamp) {
+			best.Timestamp = entry.Timestamp
+		}
+		for _, f := range entry.Results.ByFile {
+			i, ok := files[f.File]
+			if !ok {
+				files[f.File] = len(best.Results.ByFile)
+				best.Results.ByFile = append(best.Results.ByFile, compute.ByFile{File: f.File, By: bestBy(f.By)})
+				continue
+			}
+			maxBy(&best.Results.ByFile[i].By, f.By, entry.HasLines())
+		}
+		for _, p := range entry.Results.ByPackage {
+			i, ok := packages[p.Package]
+			if !ok {
+				packages[p.Package] = len(best.Results.ByPackage)
+				best.Results.ByPackage = append(best.Results.ByPackage,
+					compute.ByPackage{Package: p.Package, By: bestBy(p.By)})
+				continue
+			}
+			maxBy(&best.Results.ByPackage[i].By, p.By, entry.HasLines())
+		}
+		maxTotals(&best.Results.ByTotal, entry.Results.ByTotal, entry.HasLines())
+	}
+	return best
+}
+
+// bestBy returns the percentages of by, along with the coverage they were
+// computed from.
+func bestBy(by compute.By) compute.By {
+	return compute.By{
+		Statements:          by.Statements,
+		Blocks:              by.Blocks,
+		Lines:               by.Lines,
+		StatementPercentage: by.StatementPercentage,
+		BlockPercentage:     by.BlockPercentage,
+		LinePercentage:      by.LinePercentage,
+	}
+}
+
+// maxBy raises each percentage of best to that of by when higher. Line
+// coverage is only taken from by when lines is set, being recorded by its
+// entry, see Entry.HasLines.
+func maxBy(best *compute.By, by compute.By, lines bool) {
+	if by.StatementPercentage > best.StatementPercentage {
+		best.Statements, best.StatementPercentage = by.Statements, by.StatementPercentage
+	}
+	if by.BlockPercentage > best.BlockPercentage {
+		best.Blocks, best.BlockPercentage = by.Blocks, by.BlockPercentage
+	}
+	if lines && (best.Lines == "" || by.LinePercentage > best.LinePercentage) {
+		best.Lines, best.LinePercentage = by.Lines, by.LinePercentage
+	}
+}
+
+// maxTotals raises each total percentage of best to that of totals when
+// higher, see maxBy.
+func maxTotals(best *compute.Totals, totals compute.Totals, lines bool) {
+	if totals.Statements.Percentage > best.Statements.Percentage || best.Statements.Coverage == "" {
+		best.Statements = compute.TotalStatements{
+			Coverage: totals.Statements.Coverage, Percentage: totals.Statements.Percentage,
+		}
+	}
+	if totals.Blocks.Percentage > best.Blocks.Percentage || best.Blocks.Coverage == "" {
+		best.Blocks = compute.TotalBlocks{Coverage: totals.Blocks.Coverage, Percentage: totals.Blocks.Percentage}
+	}
+	if lines && (best.Lines.Coverage == "" || totals.Lines.Percentage > best.Lines.Percentage) {
+		best.Lines = compute.TotalLines{Coverage: totals.Lines.Coverage, Percentage: totals.Lines.Percentage}
+	}
+}
+
+// CompareBest computes the Comparison of results against the Best of the
+// History, so each delta below 0 is a shortfall from the high-water mark. Use
+// ScopeBest first to only compare against the entries of the same suite.
+// Files and packages no longer in results are not listed as removed. It
+// returns nil when the History has no entries.
+func (h *History) CompareBest(results compute.Results) *Comparison {
+	best := h.Best()
+	if best == nil {
+		return nil
+	}
+	c := Compare(BestRef, best, results)
+	c.BestOf = len(h.Entries)
+	c.RemovedFiles = []compute.ByFile{}
+	c.RemovedPackages = []compute.ByPackage{}
+	return c
+}
@@ -0,0 +1,113 @@
+package history //nolint:testpackage
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+// bestResults returns the results of one file and package with the given
+// statement, block, and line percentages, where a negative line percentage
+// means the results predate line coverage.
+func bestResults(stmts, blocks, lines float64) compute.Results {
+	by := compute.By{Statements: "s", Blocks: "b", StatementPercentage: stmts, BlockPercentage: blocks}
+	totals := compute.Totals{
+		Statements: compute.TotalStatements{Coverage: "s", Percentage: stmts},
+		Blocks:     compute.TotalBlocks{Coverage: "b", Percentage: blocks},
+	}
+	if lines >= 0 {
+		by.Lines, by.LinePercentage = "l", lines
+		totals.Lines = compute.TotalLines{Coverage: "l", Percentage: lines}
+	}
+	return compute.Results{
+		ByFile:    []compute.ByFile{{File: "pkg/math/math.go", By: by}},
+		ByPackage: []compute.ByPackage{{Package: "pkg/math", By: by}},
+		ByTotal:   totals,
+	}
+}
+
+func TestBest(t *testing.T) {
+	require.Nil(t, (&History{}).Best())
+	require.Nil(t, (&History{}).CompareBest(compute.Results{}))
+
+	h := &History{Entries: []Entry{
+		testEntry("c3", onDay(3), withResults(bestResults(70, 60, 50))),
+		testEntry("c2", onDay(2), withResults(bestResults(60, 80, -1))),
+		testEntry("c1", onDay(1), withResults(bestResults(90, 50, 40))),
+	}}
+	h.Entries[2].Results.ByFile = append(h.Entries[2].Results.ByFile,
+		compute.ByFile{File: "pkg/old/old.go", By: compute.By{StatementPercentage: 100}})
+
+	best := h.Best()
+	require.Equal(t, time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC), best.Timestamp)
+	require.Len(t, best.Results.ByFile, 2)
+	for _, by := range []compute.By{best.Results.ByFile[0].By, best.Results.ByPackage[0].By} {
+		require.InDelta(t, 90, by.StatementPercentage, 0)
+		require.InDelta(t, 80, by.BlockPercentage, 0)
+		require.InDelta(t, 50, by.LinePercentage, 0)
+		require.Equal(t, "l", by.Lines)
+	}
+	require.True(t, best.HasLines())
+	require.InDelta(t, 90, best.Results.ByTotal.Statements.Percentage, 0)
+	require.InDelta(t, 80, best.Results.ByTotal.Blocks.Percentage, 0)
+	require.InDelta(t, 50, best.Results.ByTotal.Lines.Percentage, 0)
+
+	// the current results fall short of the best statements and blocks
+	results := bestResults(85, 80, 55)
+	c := h.CompareBest(results)
+	require.Equal(t, BestRef, c.Ref)
+	require.Equal(t, 3, c.BestOf)
+	require.Empty(t, c.RemovedFiles)
+	require.Equal(t, Deltas{
+		Statements: Delta{Old: 90, New: 85, Delta: -5},
+		Blocks:     Delta{Old: 80, New: 80},
+		Lines:      &Delta{Old: 50, New: 55, Delta: 5},
+	}, c.ByTotal)
+
+	tolerance := config.RegressionTolerance{}
+	require.NoError(t, tolerance.Set("file=5"))
+	require.NoError(t, tolerance.Set("package=5"))
+	require.True(t, c.ApplyRegressionTolerance(tolerance))
+	require.Len(t, c.Regressions, 1)
+	require.Equal(t, config.RegressionScopeTotal, c.Regressions[0].Scope)
+	require.Equal(t, config.StatementsSection, c.Regressions[0].Metric)
+}
+
+func TestHistory_ScopeBest(t *testing.T) {
+	unit := testEntry("c1", withLabel("unit"), onDay(1), withResults(bestResults(60, 60, 60)))
+	unitFeature := testEntry("c2", withLabel("unit"), onBranch("feature"), onDay(2),
+		withResults(bestResults(70, 70, 70)))
+	integration := testEntry("c3", withLabel("integration"), onDay(3), withResults(bestResults(90, 90, 90)))
+	entries := []Entry{integration, unitFeature, unit}
+
+	tests := []struct {
+		scope  string
+		branch string
+		want   []Entry
+	}{
+		{scope: "", branch: "main", want: []Entry{unit}},
+		{scope: BestScopeLabelBranch, branch: "detached", want: []Entry{unitFeature, unit}},
+		{scope: BestScopeLabel, branch: "main", want: []Entry{unitFeature, unit}},
+		{scope: BestScopeAll, branch: "main", want: entries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope+"/"+tt.branch, func(t *testing.T) {
+			h := &History{Entries: slices.Clone(entries)}
+			require.NoError(t, h.ScopeBest(tt.scope, "unit", tt.branch))
+			require.Equal(t, tt.want, h.Entries)
+		})
+	}
+
+	// the unit run is not held to the best of the integration suite
+	h := &History{Entries: slices.Clone(entries)}
+	require.NoError(t, h.ScopeBest(BestScopeLabelBranch, "unit", "main"))
+	c := h.CompareBest(bestResults(60, 60, 60))
+	require.Equal(t, 1, c.BestOf)
+	require.False(t, c.ByTotal.Changed())
+
+	require.ErrorContains(t, h.ScopeBest("suite", "", ""), `invalid best scope "suite"`)
+}
@@ -0,0 +1,254 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	gitconfig "github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/storage"
+)
+
+// Branch is the default orphan branch holding go-covercheck history.
+const Branch = "covercheck-history"
+
+// branchSaveAttempts is how many times a save is tried when the branch moved
+// since it was loaded.
+const branchSaveAttempts = 5
+
+// errBranchMoved is returned by a save attempt that lost a race to update the
+// branch.
+var errBranchMoved = errors.New("history branch was updated concurrently")
+
+// branch is a Storage keeping History in a file committed on an orphan branch
+// instead of a file in the working tree. When remote is set the branch is
+// fetched from and pushed to it.
+type branch struct {
+	repoPath string
+	remote   string
+	name     string
+	file     string
+	// base and tip hold the entries and the commit they were loaded from, so
+	// a save can replay the changes made since onto a branch that moved in the
+	// meantime. tip is nil when the branch did not exist.
+	base []Entry
+	tip  *object.Commit
+}
+
+// NewBranchStorage returns a Storage keeping History in file as committed on
+// the orphan branch name of the repository at repoPath, fetched from and
+// pushed to remote when one is given. A branch that does not exist yet is an
+// empty History, and is created without parents on the first save.
+func NewBranchStorage(repoPath, remote, name, file string) Storage {
+	return &branch{repoPath: repoPath, remote: remote, name: name, file: file}
+}
+
+// LoadBranch loads History from file as committed on the orphan branch name
+// of the repository at repoPath, see NewBranchStorage.
+func LoadBranch(repoPath, remote, name, file string) (*History, error) {
+	return LoadStorage(NewBranchStorage(repoPath, remote, name, file))
+}
+
+func (b *branch) Load() ([]Entry, error) {
+	repo, err := git.PlainOpen(b.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", b.repoPath, err)
+	}
+	entries, tip, err := b.read(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	b.base, b.tip = slices.Clone(entries), tip
+	return entries, nil
+}
+
+// trackingRef is the ref holding the latest known tip of the branch.
+func (b *branch) trackingRef() plumbing.ReferenceName {
+	if b.remote != "" {
+		return plumbing.NewRemoteReferenceName(b.remote, b.name)
+	}
+	return plumbing.NewBranchReferenceName(b.name)
+}
+
+// read fetches the branch when it has a remote and returns the entries of its
+// history file along with the tip commit, which is nil when the branch does
+// not exist yet.
+func (b *branch) read(repo *git.Repository) ([]Entry, *object.Commit, error) {
+	if b.remote != "" {
+		err := repo.Fetch(&git.FetchOptions{
+			RemoteName: b.remote,
+			RefSpecs: []gitconfig.RefSpec{
+				gitconfig.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(b.name), b.trackingRef())),
+			},
+		})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) && !errors.Is(err, git.ErrRemoteRefNotFound) &&
+			!errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return nil, nil, fmt.Errorf("failed to fetch %s from %s: %w", b.name, b.remote, err)
+		}
+	}
+
+	ref, err := repo.Reference(b.trackingRef(), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	tip, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", b.name, err)
+	}
+
+	f, err := tip.File(b.file)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, tip, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	contents, err := f.Contents()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := decodeHistory([]byte(contents))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid history file %s on branch %s: %w", b.file, b.name, err)
+	}
+	return entries, tip, nil
+}
+
+// Save commits entries to the branch on top of the commit they were loaded
+// from, limited to limit when greater than 0. When the branch moved since, the
+// branch is read again, the changes made to the entries since they were loaded
+// are replayed onto its new tip, and the save retried. It returns the entries
+// as committed.
+func (b *branch) Save(entries []Entry, key string, limit int) ([]Entry, error) {
+	repo, err := git.PlainOpen(b.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", b.repoPath, err)
+	}
+
+	latest, tip := b.base, b.tip
+	for attempt := range branchSaveAttempts {
+		if attempt > 0 {
+			if latest, tip, err = b.read(repo); err != nil {
+				return nil, err
+			}
+		}
+
+		merged := limitEntries(replayChanges(b.base, entries, latest, key), limit)
+
+		commit, err := b.commit(repo, merged, tip)
+		if errors.Is(err, errBranchMoved) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		b.base, b.tip = slices.Clone(merged), commit
+		return merged, nil
+	}
+	return nil, fmt.Errorf("failed to save history to branch %s after %d attempts: %w",
+		b.name, branchSaveAttempts, errBranchMoved)
+}
+
+// commit records entries as the history file in a new commit on top of tip,
+// keeping any other files of the branch, and publishes it.
+func (b *branch) commit(repo *git.Repository, entries []Entry, tip *object.Commit) (*object.Commit, error) {
+	data, err := encodeHistory(entries)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := writeBlob(repo, data)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &object.Tree{}
+	commit := &object.Commit{Message: "Update go-covercheck history\n"}
+	if tip != nil {
+		tipTree, err := tip.Tree()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range tipTree.Entries {
+			if e.Name != b.file {
+				tree.Entries = append(tree.Entries, e)
+			}
+		}
+		commit.ParentHashes = []plumbing.Hash{tip.Hash}
+	}
+	tree.Entries = append(tree.Entries, object.TreeEntry{Name: b.file, Mode: filemode.Regular, Hash: blob})
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return tree.Entries[i].Name < tree.Entries[j].Name
+	})
+	if commit.TreeHash, err = encodeObject(repo, tree); err != nil {
+		return nil, err
+	}
+
+	commit.Author = notesAuthor
+	commit.Author.When = time.Now()
+	commit.Committer = commit.Author
+	hash, err := encodeObject(repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.publish(repo, hash, tip); err != nil {
+		return nil, err
+	}
+	return repo.CommitObject(hash)
+}
+
+// publish points the branch at commit. Without a remote the local branch is
+// compared and swapped with tip; with one the branch is pushed, which the
+// remote rejects as a non-fast-forward when another save got there first.
+func (b *branch) publish(repo *git.Repository, commit plumbing.Hash, tip *object.Commit) error {
+	local := plumbing.NewBranchReferenceName(b.name)
+	newRef := plumbing.NewHashReference(local, commit)
+
+	if b.remote == "" {
+		var old *plumbing.Reference
+		if tip != nil {
+			old = plumbing.NewHashReference(local, tip.Hash)
+		}
+		if err := repo.Storer.CheckAndSetReference(newRef, old); err != nil {
+			if errors.Is(err, storage.ErrReferenceHasChanged) {
+				return errBranchMoved
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := repo.Storer.SetReference(newRef); err != nil {
+		return err
+	}
+	err := repo.Push(&git.PushOptions{
+		RemoteName: b.remote,
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("%s:%s", local, local))},
+	})
+	switch {
+	case err == nil, errors.Is(err, git.NoErrAlreadyUpToDate):
+		return repo.Storer.SetReference(plumbing.NewHashReference(b.trackingRef(), commit))
+	case isNonFastForward(err):
+		return errBranchMoved
+	default:
+		return fmt.Errorf("failed to push %s to %s: %w", b.name, b.remote, err)
+	}
+}
+
+func isNonFastForward(err error) bool {
+	return errors.Is(err, git.ErrForceNeeded) ||
+		strings.Contains(err.Error(), "non-fast-forward") ||
+		strings.Contains(err.Error(), "fetch first")
+}
@@ -0,0 +1,178 @@
+package history //nolint:testpackage
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+	gitconfig "github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+const branchFile = ".go-covercheck.history.json"
+
+// cloneOf creates a repository with origin pointing at the bare repository
+// barePath.
+func cloneOf(t *testing.T, barePath string) string {
+	t.Helper()
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{barePath}})
+	require.NoError(t, err)
+	return repoDir
+}
+
+func TestLoadBranch_Missing(t *testing.T) {
+	repoDir, _ := initNotesRepo(t, 1)
+
+	h, err := LoadBranch(repoDir, "", Branch, branchFile)
+	require.NoError(t, err)
+	require.Empty(t, h.Entries)
+}
+
+func TestLoadBranch_NotARepository(t *testing.T) {
+	_, err := LoadBranch(t.TempDir(), "", Branch, branchFile)
+	require.ErrorContains(t, err, "failed to open git repository")
+}
+
+func TestBranch_SaveAndLoad(t *testing.T) {
+	repoDir, commits := initNotesRepo(t, 3)
+
+	h, err := LoadBranch(repoDir, "", Branch, branchFile)
+	require.NoError(t, err)
+	h.Entries = []Entry{
+		testEntry(commits[2], withLabel("third"), onDay(20)),
+		testEntry(commits[1], withLabel("second"), onDay(19)),
+		testEntry(commits[0], withLabel("first"), onDay(18)),
+	}
+	require.NoError(t, h.Save(2))
+	require.Len(t, h.Entries, 2)
+
+	loaded, err := LoadBranch(repoDir, "", Branch, branchFile)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 2)
+	require.Equal(t, "third", loaded.Entries[0].Label)
+	require.True(t, deleteByRef(t, loaded, "third"))
+	require.NoError(t, loaded.Save(0))
+
+	loaded, err = LoadBranch(repoDir, "", Branch, branchFile)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 1)
+	require.Equal(t, "second", loaded.Entries[0].Label)
+
+	// the branch is an orphan holding only the history file, and HEAD is
+	// left alone.
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(Branch), true)
+	require.NoError(t, err)
+	tip, err := repo.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	first, err := tip.Parent(0)
+	require.NoError(t, err)
+	require.Empty(t, first.ParentHashes)
+	tree, err := tip.Tree()
+	require.NoError(t, err)
+	require.Len(t, tree.Entries, 1)
+	require.Equal(t, branchFile, tree.Entries[0].Name)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, commits[2], head.Hash().String())
+}
+
+func TestBranch_SaveRetriesOnConcurrentUpdate(t *testing.T) {
+	barePath := t.TempDir()
+	_, err := git.PlainInit(barePath, true)
+	require.NoError(t, err)
+	_, commits := initNotesRepo(t, 3)
+
+	// two runners load the history before either saves.
+	first, err := LoadBranch(cloneOf(t, barePath), "origin", Branch, branchFile)
+	require.NoError(t, err)
+	second, err := LoadBranch(cloneOf(t, barePath), "origin", Branch, branchFile)
+	require.NoError(t, err)
+
+	first.Entries = append(first.Entries, testEntry(commits[0], withLabel("first"), onDay(18)))
+	require.NoError(t, first.Save(0))
+
+	// the second push is rejected as a non-fast-forward, so its entry is
+	// replayed onto the first and pushed again.
+	second.Entries = append(second.Entries, testEntry(commits[1], withLabel("second"), onDay(19)))
+	require.NoError(t, second.Save(0))
+	require.Len(t, second.Entries, 2)
+
+	// a later change on the first runner keeps the entry of the second.
+	require.True(t, deleteByRef(t, first, "first"))
+	first.Entries = append(first.Entries, testEntry(commits[2], withLabel("third"), onDay(20)))
+	require.NoError(t, first.Save(0))
+
+	loaded, err := LoadBranch(cloneOf(t, barePath), "origin", Branch, branchFile)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 2)
+	require.Equal(t, "third", loaded.Entries[0].Label)
+	require.Equal(t, "second", loaded.Entries[1].Label)
+
+	bare, err := git.PlainOpen(barePath)
+	require.NoError(t, err)
+	ref, err := bare.Reference(plumbing.NewBranchReferenceName(Branch), true)
+	require.NoError(t, err)
+	commitsOnBranch := 0
+	iter, err := bare.Log(&git.LogOptions{From: ref.Hash()})
+	require.NoError(t, err)
+	require.NoError(t, iter.ForEach(func(*object.Commit) error {
+		commitsOnBranch++
+		return nil
+	}))
+	require.Equal(t, 3, commitsOnBranch)
+}
+
+func TestBranch_InterleavedSavesByCommit(t *testing.T) {
+	barePath := t.TempDir()
+	_, err := git.PlainInit(barePath, true)
+	require.NoError(t, err)
+
+	first, err := LoadBranch(cloneOf(t, barePath), "origin", Branch, branchFile)
+	require.NoError(t, err)
+	second, err := LoadBranch(cloneOf(t, barePath), "origin", Branch, branchFile)
+	require.NoError(t, err)
+	require.NoError(t, first.SetKey(KeyCommit))
+	require.NoError(t, second.SetKey(KeyCommit))
+
+	first.Entries = append(first.Entries, testEntry("aaa", withLabel("unit"), onDay(18)))
+	require.NoError(t, first.Save(0))
+
+	// under the commit key the entry of the second runner replaces the one
+	// of the first rather than being kept beside it.
+	second.Entries = append(second.Entries, testEntry("aaa", withLabel("integration"), onDay(19)))
+	require.NoError(t, second.Save(0))
+
+	loaded, err := LoadBranch(cloneOf(t, barePath), "origin", Branch, branchFile)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 1)
+	require.Equal(t, "integration", findByRef(t, loaded, "aaa").Label)
+}
+
+func TestReplayChanges(t *testing.T) {
+	a := testEntry("a", onDay(1))
+	b := testEntry("b", onDay(2))
+	c := testEntry("c", onDay(3))
+	bChanged := testEntry("b", withLabel("changed"), onDay(4))
+	d := testEntry("d", onDay(5))
+
+	// b was changed and a removed since base, while c was added by another
+	// writer.
+	merged := replayChanges([]Entry{b, a}, []Entry{bChanged}, []Entry{c, b, a}, KeyCommitLabel)
+	require.Equal(t, []Entry{bChanged, c}, merged)
+
+	// entries removed concurrently stay removed unless changed locally.
+	merged = replayChanges([]Entry{b, a}, []Entry{d, b, a}, []Entry{c}, KeyCommitLabel)
+	require.Equal(t, []Entry{d, c}, merged)
+
+	// under the commit key an entry of another label of the same commit is
+	// replaced rather than kept beside the changed one.
+	bOther := testEntry("b", withLabel("other"), onDay(6))
+	merged = replayChanges([]Entry{a}, []Entry{bChanged, a}, []Entry{bOther, a}, KeyCommit)
+	require.Equal(t, []Entry{bChanged, a}, merged)
+}
@@ -0,0 +1,249 @@
+package history
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// getenv looks up environment variables when detecting the Build of an Entry.
+//
+// Defined as a package variable to allow testing with different environments.
+var getenv = os.Getenv
+
+// goEnvVersion returns the version of the go toolchain on the PATH, or "" when
+// there is none.
+//
+// Defined as a package variable to allow testing without running go.
+var goEnvVersion = func() string {
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Build holds metadata of the CI build that recorded an Entry, so a coverage
+// point can be traced back to the build that produced it.
+type Build struct {
+	// Provider is the CI provider, such as github-actions or gitlab.
+	Provider string `json:"provider,omitempty"    yaml:"provider,omitempty"`
+	// Number is the build number of the provider.
+	Number string `json:"number,omitempty"      yaml:"number,omitempty"`
+	// URL links to the build.
+	URL string `json:"url,omitempty"         yaml:"url,omitempty"`
+	// PullRequest is the number of the pull or merge request built.
+	PullRequest string `json:"pullRequest,omitempty" yaml:"pullRequest,omitempty"`
+	// Pipeline identifies the pipeline or workflow run of the build.
+	Pipeline string `json:"pipeline,omitempty"    yaml:"pipeline,omitempty"`
+	// Runner is the name of the agent or runner the build ran on.
+	Runner string `json:"runner,omitempty"      yaml:"runner,omitempty"`
+	// GoVersion is the version of Go the covered code was built with, read
+	// from the GOVERSION environment variable or else from the go toolchain
+	// on the PATH, as reported by "go env GOVERSION".
+	GoVersion string `json:"goVersion,omitempty"   yaml:"goVersion,omitempty"`
+	// Metadata holds user-provided key=value pairs.
+	Metadata map[string]string `json:"metadata,omitempty"    yaml:"metadata,omitempty"`
+}
+
+// ciProvider reads the Build of a CI provider from its environment variables.
+type ciProvider struct {
+	name string
+	// detect is set by the provider in its builds.
+	detect string
+	build  func(env func(string) string) Build
+}
+
+// ciProviders are the well-known CI providers a Build is detected for.
+var ciProviders = []ciProvider{
+	{name: "github-actions", detect: "GITHUB_ACTIONS", build: func(env func(string) string) Build {
+		b := Build{Number: env("GITHUB_RUN_NUMBER"), Pipeline: env("GITHUB_RUN_ID"), Runner: env("RUNNER_NAME")}
+		if server, repo := env("GITHUB_SERVER_URL"), env("GITHUB_REPOSITORY"); server != "" && repo != "" &&
+			b.Pipeline != "" {
+			b.URL = server + "/" + repo + "/actions/runs/" + b.Pipeline
+		}
+		// pull request builds check out refs/pull/<number>/merge
+		if ref, found := strings.CutPrefix(env("GITHUB_REF"), "refs/pull/"); found {
+			b.PullRequest, _, _ = strings.Cut(ref, "/")
+		}
+		return b
+	}},
+	{name: "gitlab", detect: "GITLAB_CI", build: func(env func(string) string) Build {
+		return Build{
+			Number:      env("CI_JOB_ID"),
+			URL:         env("CI_JOB_URL"),
+			PullRequest: env("CI_MERGE_REQUEST_IID"),
+			Pipeline:    env("CI_PIPELINE_ID"),
+			Runner:      env("CI_RUNNER_DESCRIPTION"),
+		}
+	}},
+	{name: "circleci", detect: "CIRCLECI", build: func(env func(string) string) Build {
+		b := Build{
+			Number:      env("CIRCLE_BUILD_NUM"),
+			URL:         env("CIRCLE_BUILD_URL"),
+			PullRequest: env("CIRCLE_PR_NUMBER"),
+			Pipeline:    env("CIRCLE_WORKFLOW_ID"),
+		}
+		if pr := env("CIRCLE_PULL_REQUEST"); b.PullRequest == "" && pr != "" {
+			b.PullRequest = pr[strings.LastIndex(pr, "/")+1:]
+		}
+		return b
+	}},
+	{name: "jenkins", detect: "JENKINS_URL", build: func(env func(string) string) Build {
+		return Build{
+			Number:      env("BUILD_NUMBER"),
+			URL:         env("BUILD_URL"),
+			PullRequest: env("CHANGE_ID"),
+			Pipeline:    env("JOB_NAME"),
+			Runner:      env("NODE_NAME"),
+		}
+	}},
+	{name: "buildkite", detect: "BUILDKITE", build: func(env func(string) string) Build {
+		b := Build{
+			Number:   env("BUILDKITE_BUILD_NUMBER"),
+			URL:      env("BUILDKITE_BUILD_URL"),
+			Pipeline: env("BUILDKITE_PIPELINE_SLUG"),
+			Runner:   env("BUILDKITE_AGENT_NAME"),
+		}
+		if pr := env("BUILDKITE_PULL_REQUEST"); pr != "false" {
+			b.PullRequest = pr
+		}
+		return b
+	}},
+	{name: "azure-pipelines", detect: "TF_BUILD", build: func(env func(string) string) Build {
+		b := Build{
+			Number:      env("BUILD_BUILDNUMBER"),
+			PullRequest: env("SYSTEM_PULLREQUEST_PULLREQUESTNUMBER"),
+			Pipeline:    env("SYSTEM_DEFINITIONID"),
+			Runner:      env("AGENT_NAME"),
+		}
+		if b.PullRequest == "" {
+			b.PullRequest = env("SYSTEM_PULLREQUEST_PULLREQUESTID")
+		}
+		if collection, id := env("SYSTEM_COLLECTIONURI"), env("BUILD_BUILDID"); collection != "" && id != "" {
+			b.URL = collection + env("SYSTEM_TEAMPROJECT") + "/_build/results?buildId=" + id
+		}
+		return b
+	}},
+	{name: "bitbucket", detect: "BITBUCKET_BUILD_NUMBER", build: func(env func(string) string) Build {
+		b := Build{
+			Number:      env("BITBUCKET_BUILD_NUMBER"),
+			PullRequest: env("BITBUCKET_PR_ID"),
+			Pipeline:    env("BITBUCKET_PIPELINE_UUID"),
+		}
+		if repo := env("BITBUCKET_REPO_FULL_NAME"); repo != "" {
+			b.URL = "https://bitbucket.org/" + repo + "/pipelines/results/" + b.Number
+		}
+		return b
+	}},
+}
+
+// DetectBuild returns the Build of the CI provider detected from the
+// environment, if any, along with the Go version and the metadata. The Go
+// version is that of the GOVERSION environment variable, or else of the go
+// toolchain on the PATH. It returns nil when none of them is found.
+func DetectBuild(metadata map[string]string) *Build {
+	b := Build{}
+	for _, p := range ciProviders {
+		if getenv(p.detect) != "" {
+			b = p.build(getenv)
+			b.Provider = p.name
+			break
+		}
+	}
+	if b.GoVersion = getenv("GOVERSION"); b.GoVersion == "" {
+		b.GoVersion = goEnvVersion()
+	}
+	if len(metadata) > 0 {
+		b.Metadata = maps.Clone(metadata)
+	}
+	if b.Provider == "" && b.GoVersion == "" && b.Metadata == nil {
+		return nil
+	}
+	return &b
+}
+
+// ParsePairs parses pairs given as key=value, such as metadata or a filter of
+// Build fields, into a map. Keys must not be empty.
+func ParsePairs(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil //nolint:nilnil // no pairs given
+	}
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid pair %q, expected key=value", pair)
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+// Field returns the value of the Build field named by key, being one of the
+// json names of its fields such as number or pullRequest, or else the
+// metadata of key.
+func (b *Build) Field(key string) string {
+	if b == nil {
+		return ""
+	}
+	switch key {
+	case "provider":
+		return b.Provider
+	case "number":
+		return b.Number
+	case "url":
+		return b.URL
+	case "pullRequest":
+		return b.PullRequest
+	case "pipeline":
+		return b.Pipeline
+	case "runner":
+		return b.Runner
+	case "goVersion":
+		return b.GoVersion
+	default:
+		return b.Metadata[key]
+	}
+}
+
+// Matches reports whether each key of filter, see Field, has its value.
+func (b *Build) Matches(filter map[string]string) bool {
+	for key, value := range filter {
+		if b.Field(key) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary returns a short description of the Build, such as
+// "github-actions #42, PR #7, runner-1, go1.26.0, env=prod", without its URL.
+func (b *Build) Summary() string {
+	if b == nil {
+		return ""
+	}
+	var parts []string
+	switch {
+	case b.Provider != "" && b.Number != "":
+		parts = append(parts, b.Provider+" #"+b.Number)
+	case b.Provider != "":
+		parts = append(parts, b.Provider)
+	}
+	if b.PullRequest != "" {
+		parts = append(parts, "PR #"+b.PullRequest)
+	}
+	for _, part := range []string{b.Runner, b.GoVersion} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(b.Metadata)) {
+		parts = append(parts, key+"="+b.Metadata[key])
+	}
+	return strings.Join(parts, ", ")
+}
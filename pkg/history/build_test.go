@@ -0,0 +1,160 @@
+package history //nolint:testpackage
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/stretchr/testify/require"
+)
+
+// setEnv replaces the environment DetectBuild reads for the test, where no go
+// toolchain is on the PATH.
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	setGoToolchain(t, "")
+	getenv = func(key string) string {
+		return env[key]
+	}
+	t.Cleanup(func() {
+		getenv = os.Getenv
+	})
+}
+
+// setGoToolchain makes the go toolchain on the PATH report version, or makes it
+// missing when version is empty.
+func setGoToolchain(t *testing.T, version string) {
+	t.Helper()
+	previous := goEnvVersion
+	goEnvVersion = func() string {
+		return version
+	}
+	t.Cleanup(func() {
+		goEnvVersion = previous
+	})
+}
+
+func TestDetectBuild(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *Build
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "go version",
+			env:  map[string]string{"GOVERSION": "go1.26.0"},
+			want: &Build{GoVersion: "go1.26.0"},
+		},
+		{
+			name: "github-actions",
+			env: map[string]string{
+				"GITHUB_ACTIONS": "true", "GITHUB_RUN_NUMBER": "42", "GITHUB_RUN_ID": "123456",
+				"GITHUB_SERVER_URL": "https://github.com", "GITHUB_REPOSITORY": "mach6/go-covercheck",
+				"GITHUB_REF": "refs/pull/7/merge", "RUNNER_NAME": "runner-1",
+			},
+			want: &Build{
+				Provider: "github-actions", Number: "42", Pipeline: "123456", Runner: "runner-1", PullRequest: "7",
+				URL: "https://github.com/mach6/go-covercheck/actions/runs/123456",
+			},
+		},
+		{
+			name: "gitlab",
+			env: map[string]string{
+				"GITLAB_CI": "true", "CI_JOB_ID": "9", "CI_JOB_URL": "https://gitlab.com/jobs/9",
+				"CI_MERGE_REQUEST_IID": "3", "CI_PIPELINE_ID": "77",
+			},
+			want: &Build{
+				Provider: "gitlab", Number: "9", URL: "https://gitlab.com/jobs/9", PullRequest: "3", Pipeline: "77",
+			},
+		},
+		{
+			name: "circleci",
+			env: map[string]string{
+				"CIRCLECI": "true", "CIRCLE_BUILD_NUM": "5",
+				"CIRCLE_PULL_REQUEST": "https://github.com/mach6/go-covercheck/pull/11",
+			},
+			want: &Build{Provider: "circleci", Number: "5", PullRequest: "11"},
+		},
+		{
+			name: "buildkite",
+			env:  map[string]string{"BUILDKITE": "true", "BUILDKITE_BUILD_NUMBER": "8", "BUILDKITE_PULL_REQUEST": "false"},
+			want: &Build{Provider: "buildkite", Number: "8"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(t, tt.env)
+			require.Equal(t, tt.want, DetectBuild(nil))
+		})
+	}
+}
+
+func TestDetectBuild_GoToolchain(t *testing.T) {
+	// the tests run with a go toolchain on the PATH
+	require.True(t, strings.HasPrefix(goEnvVersion(), "go"))
+
+	setEnv(t, nil)
+	setGoToolchain(t, "go1.25.3")
+	require.Equal(t, &Build{GoVersion: "go1.25.3"}, DetectBuild(nil))
+
+	// GOVERSION takes precedence over the toolchain
+	setEnv(t, map[string]string{"GOVERSION": "go1.26.0"})
+	setGoToolchain(t, "go1.25.3")
+	require.Equal(t, &Build{GoVersion: "go1.26.0"}, DetectBuild(nil))
+}
+
+func TestHistory_AddResults_RecordsBuild(t *testing.T) {
+	setEnv(t, map[string]string{
+		"JENKINS_URL": "https://ci", "BUILD_NUMBER": "12", "NODE_NAME": "agent", "GOVERSION": "go1.26.0",
+	})
+
+	h := New("")
+	h.SetMetadata(map[string]string{"env": "staging"})
+	h.AddResults(compute.Results{}, "")
+
+	build := h.Entries[0].Build
+	require.NotNil(t, build)
+	require.Equal(t, "jenkins", build.Provider)
+	require.Equal(t, "staging", build.Metadata["env"])
+	require.Equal(t, "jenkins #12, agent, go1.26.0, env=staging", build.Summary())
+}
+
+func TestHistory_AddResults_NoBuild(t *testing.T) {
+	setEnv(t, nil)
+
+	h := New("")
+	h.AddResults(compute.Results{}, "")
+	require.Nil(t, h.Entries[0].Build)
+}
+
+func TestParsePairs(t *testing.T) {
+	pairs, err := ParsePairs([]string{"env=staging", "team = core", "empty="})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"env": "staging", "team": " core", "empty": ""}, pairs)
+
+	pairs, err = ParsePairs(nil)
+	require.NoError(t, err)
+	require.Nil(t, pairs)
+
+	_, err = ParsePairs([]string{"env"})
+	require.ErrorContains(t, err, `invalid pair "env", expected key=value`)
+	_, err = ParsePairs([]string{"=staging"})
+	require.Error(t, err)
+}
+
+func TestBuild_Matches(t *testing.T) {
+	build := &Build{Provider: "gitlab", PullRequest: "3", Metadata: map[string]string{"env": "staging"}}
+
+	require.True(t, build.Matches(nil))
+	require.True(t, build.Matches(map[string]string{"provider": "gitlab", "pullRequest": "3", "env": "staging"}))
+	require.False(t, build.Matches(map[string]string{"pullRequest": "4"}))
+	require.False(t, build.Matches(map[string]string{"env": "prod"}))
+
+	var none *Build
+	require.False(t, none.Matches(map[string]string{"provider": "gitlab"}))
+	require.Empty(t, none.Summary())
+}
@@ -0,0 +1,287 @@
+package history
+
+import (
+	"time"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/config"
+)
+
+// Delta holds the old (historical) and new (current) percentages of a
+// coverage metric along with their difference.
+type Delta struct {
+	Old   float64 `json:"old"   yaml:"old"`
+	New   float64 `json:"new"   yaml:"new"`
+	Delta float64 `json:"delta" yaml:"delta"`
+}
+
+// Deltas holds the Delta of each coverage metric. Lines is nil when the
+// historical entry predates line coverage.
+type Deltas struct {
+	Statements Delta  `json:"statements"      yaml:"statements"`
+	Blocks     Delta  `json:"blocks"          yaml:"blocks"`
+	Lines      *Delta `json:"lines,omitempty" yaml:"lines,omitempty"`
+}
+
+// Changed reports whether any metric differs from the historical value.
+func (d Deltas) Changed() bool {
+	return d.Statements.Delta != 0 || d.Blocks.Delta != 0 || (d.Lines != nil && d.Lines.Delta != 0)
+}
+
+// FileDelta holds the Deltas of a file present in both the current results
+// and the historical entry.
+type FileDelta struct {
+	File   string `json:"file" yaml:"file"`
+	Deltas `yaml:",inline"`
+}
+
+// PackageDelta holds the Deltas of a package present in both the current
+// results and the historical entry.
+type PackageDelta struct {
+	Package string `json:"package" yaml:"package"`
+	Deltas  `yaml:",inline"`
+}
+
+// Comparison holds the differences between current results and a historical
+// Entry. ByFile and ByPackage only list entries whose coverage changed; files
+// and packages that exist on one side only are listed as added or removed.
+type Comparison struct {
+	Ref             string              `json:"ref"             yaml:"ref"`
+	Commit          string              `json:"commit"          yaml:"commit"`
+	Branch          string              `json:"branch"          yaml:"branch"`
+	Tags            []string            `json:"tags,omitempty"  yaml:"tags,omitempty"`
+	Label           string              `json:"label,omitempty" yaml:"label,omitempty"`
+	Timestamp       time.Time           `json:"timestamp"       yaml:"timestamp"`
+	ByFile          []FileDelta         `json:"byFile"          yaml:"byFile"`
+	ByPackage       []PackageDelta      `json:"byPackage"       yaml:"byPackage"`
+	ByTotal         Deltas              `json:"byTotal"         yaml:"byTotal"`
+	AddedFiles      []compute.ByFile    `json:"addedFiles"      yaml:"addedFiles"`
+	RemovedFiles    []compute.ByFile    `json:"removedFiles"    yaml:"removedFiles"`
+	AddedPackages   []compute.ByPackage `json:"addedPackages"   yaml:"addedPackages"`
+	RemovedPackages []compute.ByPackage `json:"removedPackages" yaml:"removedPackages"`
+	// NewFileThreshold and FailedNewFiles are set by ApplyNewFileThreshold.
+	NewFileThreshold float64  `json:"newFileThreshold,omitempty" yaml:"newFileThreshold,omitempty"`
+	FailedNewFiles   []string `json:"failedNewFiles,omitempty"   yaml:"failedNewFiles,omitempty"`
+	// Regressions is set by ApplyRegressionTolerance.
+	Regressions []Regression `json:"regressions,omitempty" yaml:"regressions,omitempty"`
+	// BestOf is set by CompareBest to the number of entries whose best values
+	// are compared against.
+	BestOf int `json:"bestOf,omitempty" yaml:"bestOf,omitempty"`
+	// Lines is set by ApplyLines or CompareEntries when both sides recorded
+	// the line detail of their files.
+	Lines []LineChange `json:"lines,omitempty" yaml:"lines,omitempty"`
+	// Target is set when the compared results come from a history entry
+	// rather than a coverage profile.
+	Target *Target `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+// Target identifies the history entry whose results are compared against the
+// ref entry by CompareEntries.
+type Target struct {
+	Ref       string    `json:"ref"             yaml:"ref"`
+	Commit    string    `json:"commit"          yaml:"commit"`
+	Branch    string    `json:"branch"          yaml:"branch"`
+	Tags      []string  `json:"tags,omitempty"  yaml:"tags,omitempty"`
+	Label     string    `json:"label,omitempty" yaml:"label,omitempty"`
+	Timestamp time.Time `json:"timestamp"       yaml:"timestamp"`
+}
+
+// Regression is a coverage drop beyond the allowed tolerance.
+type Regression struct {
+	Scope     string `json:"scope"     yaml:"scope"`
+	Name      string `json:"name"      yaml:"name"`
+	Metric    string `json:"metric"    yaml:"metric"`
+	Delta     `yaml:",inline"`
+	Tolerance float64 `json:"tolerance" yaml:"tolerance"`
+}
+
+// Changed reports whether the comparison found any difference.
+func (c *Comparison) Changed() bool {
+	return len(c.ByFile) > 0 || len(c.ByPackage) > 0 || c.ByTotal.Changed() ||
+		len(c.AddedFiles) > 0 || len(c.RemovedFiles) > 0 ||
+		len(c.AddedPackages) > 0 || len(c.RemovedPackages) > 0 || len(c.Lines) > 0
+}
+
+// Failed reports whether ApplyNewFileThreshold or ApplyRegressionTolerance
+// found a new file below threshold or a regression.
+func (c *Comparison) Failed() bool {
+	return len(c.FailedNewFiles) > 0 || len(c.Regressions) > 0
+}
+
+// ApplyNewFileThreshold records the added files with statement, block, or line
+// coverage below threshold in FailedNewFiles and reports whether there are
+// any. A threshold of 0 disables the check.
+func (c *Comparison) ApplyNewFileThreshold(threshold float64) bool {
+	if threshold <= 0 {
+		return false
+	}
+	c.NewFileThreshold = threshold
+	c.FailedNewFiles = nil
+	for _, f := range c.AddedFiles {
+		if f.StatementPercentage < threshold || f.BlockPercentage < threshold || f.LinePercentage < threshold {
+			c.FailedNewFiles = append(c.FailedNewFiles, f.File)
+		}
+	}
+	return len(c.FailedNewFiles) > 0
+}
+
+// ApplyLines records in Lines the line ranges of the files that lost or gained
+// coverage since the compared entry, see CompareLines, when the entry recorded
+// the line detail of its files. The files are those of the results at HEAD.
+func (c *Comparison) ApplyLines(entry *Entry, files []FileLines) {
+	if len(entry.Files) == 0 || len(files) == 0 {
+		return
+	}
+	c.Lines = CompareLines(entry.Commit, entry.Files, "", files)
+}
+
+// ApplyRegressionTolerance records in Regressions each file, package, and
+// total metric that dropped by more than its tolerance and reports whether
+// there are any.
+func (c *Comparison) ApplyRegressionTolerance(tolerance config.RegressionTolerance) bool {
+	c.Regressions = nil
+	for _, d := range c.ByFile {
+		c.appendRegressions(config.RegressionScopeFile, d.File, d.Deltas, tolerance)
+	}
+	for _, d := range c.ByPackage {
+		c.appendRegressions(config.RegressionScopePackage, d.Package, d.Deltas, tolerance)
+	}
+	c.appendRegressions(config.RegressionScopeTotal, "total", c.ByTotal, tolerance)
+	return len(c.Regressions) > 0
+}
+
+func (c *Comparison) appendRegressions(scope, name string, d Deltas, tolerance config.RegressionTolerance) {
+	metrics := []struct {
+		name  string
+		delta *Delta
+	}{
+		{config.StatementsSection, &d.Statements},
+		{config.BlocksSection, &d.Blocks},
+		{config.LinesSection, d.Lines},
+	}
+	for _, m := range metrics {
+		if m.delta == nil {
+			continue
+		}
+		allowed := tolerance.For(scope, m.name)
+		if -m.delta.Delta > allowed {
+			c.Regressions = append(c.Regressions, Regression{
+				Scope:     scope,
+				Name:      name,
+				Metric:    m.name,
+				Delta:     *m.delta,
+				Tolerance: allowed,
+			})
+		}
+	}
+}
+
+// Compare computes the Comparison of results against the historical entry
+// found for ref.
+func Compare(ref string, entry *Entry, results compute.Results) *Comparison {
+	c := &Comparison{
+		Ref:             ref,
+		Commit:          entry.Commit,
+		Branch:          entry.Branch,
+		Tags:            entry.Tags,
+		Label:           entry.Label,
+		Timestamp:       entry.Timestamp,
+		ByFile:          []FileDelta{},
+		ByPackage:       []PackageDelta{},
+		AddedFiles:      []compute.ByFile{},
+		RemovedFiles:    []compute.ByFile{},
+		AddedPackages:   []compute.ByPackage{},
+		RemovedPackages: []compute.ByPackage{},
+	}
+
+	lines := entry.HasLines()
+	prevFiles := make(map[string]compute.ByFile, len(entry.Results.ByFile))
+	for _, prev := range entry.Results.ByFile {
+		prevFiles[prev.File] = prev
+	}
+	currFiles := make(map[string]bool, len(results.ByFile))
+	for _, curr := range results.ByFile {
+		currFiles[curr.File] = true
+		prev, ok := prevFiles[curr.File]
+		if !ok {
+			c.AddedFiles = append(c.AddedFiles, curr)
+			continue
+		}
+		if d := byDeltas(prev.By, curr.By, lines); d.Changed() {
+			c.ByFile = append(c.ByFile, FileDelta{File: curr.File, Deltas: d})
+		}
+	}
+	for _, prev := range entry.Results.ByFile {
+		if !currFiles[prev.File] {
+			c.RemovedFiles = append(c.RemovedFiles, prev)
+		}
+	}
+
+	prevPackages := make(map[string]compute.ByPackage, len(entry.Results.ByPackage))
+	for _, prev := range entry.Results.ByPackage {
+		prevPackages[prev.Package] = prev
+	}
+	currPackages := make(map[string]bool, len(results.ByPackage))
+	for _, curr := range results.ByPackage {
+		currPackages[curr.Package] = true
+		prev, ok := prevPackages[curr.Package]
+		if !ok {
+			c.AddedPackages = append(c.AddedPackages, curr)
+			continue
+		}
+		if d := byDeltas(prev.By, curr.By, lines); d.Changed() {
+			c.ByPackage = append(c.ByPackage, PackageDelta{Package: curr.Package, Deltas: d})
+		}
+	}
+	for _, prev := range entry.Results.ByPackage {
+		if !currPackages[prev.Package] {
+			c.RemovedPackages = append(c.RemovedPackages, prev)
+		}
+	}
+
+	prev, curr := entry.Results.ByTotal, results.ByTotal
+	c.ByTotal = Deltas{
+		Statements: newDelta(prev.Statements.Percentage, curr.Statements.Percentage),
+		Blocks:     newDelta(prev.Blocks.Percentage, curr.Blocks.Percentage),
+	}
+	if lines {
+		d := newDelta(prev.Lines.Percentage, curr.Lines.Percentage)
+		c.ByTotal.Lines = &d
+	}
+	return c
+}
+
+// CompareEntries computes the Comparison of the results of the history entry
+// found for toRef against the one found for fromRef.
+func CompareEntries(fromRef string, from *Entry, toRef string, to *Entry) *Comparison {
+	c := Compare(fromRef, from, to.Results)
+	c.Target = &Target{
+		Ref:       toRef,
+		Commit:    to.Commit,
+		Branch:    to.Branch,
+		Tags:      to.Tags,
+		Label:     to.Label,
+		Timestamp: to.Timestamp,
+	}
+	if len(from.Files) > 0 && len(to.Files) > 0 {
+		c.Lines = CompareLines(from.Commit, from.Files, to.Commit, to.Files)
+	}
+	return c
+}
+
+// byDeltas returns the Deltas of curr from prev, including lines when set.
+func byDeltas(prev, curr compute.By, lines bool) Deltas {
+	d := Deltas{
+		Statements: newDelta(prev.StatementPercentage, curr.StatementPercentage),
+		Blocks:     newDelta(prev.BlockPercentage, curr.BlockPercentage),
+	}
+	if lines {
+		l := newDelta(prev.LinePercentage, curr.LinePercentage)
+		d.Lines = &l
+	}
+	return d
+}
+
+func newDelta(prev, curr float64) Delta {
+	return Delta{Old: prev, New: curr, Delta: curr - prev}
+}
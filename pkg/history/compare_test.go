@@ -0,0 +1,210 @@
+package history //nolint:testpackage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	ts := time.Date(2025, 7, 18, 0, 0, 0, 0, time.UTC)
+	entry := &Entry{
+		Commit:    "e40262964cc4",
+		Branch:    "main",
+		Label:     "nightly",
+		Timestamp: ts,
+		Results: compute.Results{
+			ByFile: []compute.ByFile{
+				{File: "a/a.go", By: compute.By{StatementPercentage: 50, BlockPercentage: 40, Lines: "1/2", LinePercentage: 50}},
+				{File: "a/same.go", By: compute.By{StatementPercentage: 80, BlockPercentage: 80, Lines: "4/5", LinePercentage: 80}},
+				{File: "b/gone.go", By: compute.By{StatementPercentage: 10, Lines: "0/1"}},
+			},
+			ByPackage: []compute.ByPackage{
+				{Package: "a", By: compute.By{StatementPercentage: 60, BlockPercentage: 60, Lines: "5/8", LinePercentage: 62.5}},
+				{Package: "b", By: compute.By{StatementPercentage: 10, Lines: "0/1"}},
+			},
+			ByTotal: compute.Totals{
+				Statements: compute.TotalStatements{Percentage: 50},
+				Blocks:     compute.TotalBlocks{Percentage: 40},
+				Lines:      compute.TotalLines{Coverage: "3/6", Percentage: 50},
+			},
+		},
+	}
+	results := compute.Results{
+		ByFile: []compute.ByFile{
+			{File: "a/a.go", By: compute.By{StatementPercentage: 75, BlockPercentage: 40, LinePercentage: 25}},
+			{File: "a/same.go", By: compute.By{StatementPercentage: 80, BlockPercentage: 80, LinePercentage: 80}},
+			{File: "c/new.go", By: compute.By{StatementPercentage: 100}},
+		},
+		ByPackage: []compute.ByPackage{
+			{Package: "a", By: compute.By{StatementPercentage: 70, BlockPercentage: 60, LinePercentage: 90}},
+			{Package: "c", By: compute.By{StatementPercentage: 100}},
+		},
+		ByTotal: compute.Totals{
+			Statements: compute.TotalStatements{Percentage: 60},
+			Blocks:     compute.TotalBlocks{Percentage: 40},
+			Lines:      compute.TotalLines{Percentage: 45},
+		},
+	}
+
+	c := Compare("nightly", entry, results)
+	require.True(t, c.Changed())
+	require.Equal(t, "nightly", c.Ref)
+	require.Equal(t, "e40262964cc4", c.Commit)
+	require.Equal(t, "main", c.Branch)
+	require.Equal(t, "nightly", c.Label)
+	require.Equal(t, ts, c.Timestamp)
+
+	require.Equal(t, []FileDelta{{
+		File: "a/a.go",
+		Deltas: Deltas{
+			Statements: Delta{Old: 50, New: 75, Delta: 25},
+			Blocks:     Delta{Old: 40, New: 40, Delta: 0},
+			Lines:      &Delta{Old: 50, New: 25, Delta: -25},
+		},
+	}}, c.ByFile)
+
+	require.Equal(t, []PackageDelta{{
+		Package: "a",
+		Deltas: Deltas{
+			Statements: Delta{Old: 60, New: 70, Delta: 10},
+			Blocks:     Delta{Old: 60, New: 60, Delta: 0},
+			Lines:      &Delta{Old: 62.5, New: 90, Delta: 27.5},
+		},
+	}}, c.ByPackage)
+
+	require.Equal(t, Deltas{
+		Statements: Delta{Old: 50, New: 60, Delta: 10},
+		Blocks:     Delta{Old: 40, New: 40, Delta: 0},
+		Lines:      &Delta{Old: 50, New: 45, Delta: -5},
+	}, c.ByTotal)
+
+	require.Len(t, c.AddedFiles, 1)
+	require.Equal(t, "c/new.go", c.AddedFiles[0].File)
+	require.Len(t, c.RemovedFiles, 1)
+	require.Equal(t, "b/gone.go", c.RemovedFiles[0].File)
+	require.Len(t, c.AddedPackages, 1)
+	require.Equal(t, "c", c.AddedPackages[0].Package)
+	require.Len(t, c.RemovedPackages, 1)
+	require.Equal(t, "b", c.RemovedPackages[0].Package)
+}
+
+func TestCompare_NoChange(t *testing.T) {
+	results := compute.Results{
+		ByFile: []compute.ByFile{
+			{File: "a/a.go", By: compute.By{StatementPercentage: 50}},
+		},
+		ByPackage: []compute.ByPackage{
+			{Package: "a", By: compute.By{StatementPercentage: 50}},
+		},
+		ByTotal: compute.Totals{
+			Statements: compute.TotalStatements{Percentage: 50},
+		},
+	}
+	entry := &Entry{Commit: "abc1234", Results: results}
+
+	c := Compare("abc1234", entry, results)
+	require.False(t, c.Changed())
+	require.Empty(t, c.ByFile)
+	require.Empty(t, c.ByPackage)
+	require.NotNil(t, c.AddedFiles)
+	require.Nil(t, c.ByTotal.Lines)
+}
+
+func TestComparison_ApplyNewFileThreshold(t *testing.T) {
+	c := &Comparison{
+		AddedFiles: []compute.ByFile{
+			{File: "good.go", By: compute.By{StatementPercentage: 90, BlockPercentage: 90, LinePercentage: 90}},
+			{File: "lines.go", By: compute.By{StatementPercentage: 90, BlockPercentage: 90, LinePercentage: 70}},
+			{File: "bad.go", By: compute.By{StatementPercentage: 10, BlockPercentage: 90, LinePercentage: 90}},
+		},
+	}
+
+	require.False(t, c.ApplyNewFileThreshold(0))
+	require.Empty(t, c.FailedNewFiles)
+	require.Zero(t, c.NewFileThreshold)
+
+	require.True(t, c.ApplyNewFileThreshold(80))
+	require.InDelta(t, 80.0, c.NewFileThreshold, 0)
+	require.Equal(t, []string{"lines.go", "bad.go"}, c.FailedNewFiles)
+
+	require.False(t, c.ApplyNewFileThreshold(5))
+	require.Empty(t, c.FailedNewFiles)
+}
+
+func TestComparison_ApplyRegressionTolerance(t *testing.T) {
+	c := &Comparison{
+		ByFile: []FileDelta{
+			{File: "a.go", Deltas: Deltas{
+				Statements: Delta{Old: 80, New: 79.5, Delta: -0.5},
+				Blocks:     Delta{Old: 80, New: 70, Delta: -10},
+				Lines:      &Delta{Old: 80, New: 90, Delta: 10},
+			}},
+		},
+		ByPackage: []PackageDelta{
+			{Package: "pkg", Deltas: Deltas{
+				Statements: Delta{Old: 80, New: 78, Delta: -2},
+			}},
+		},
+		ByTotal: Deltas{
+			Blocks: Delta{Old: 60, New: 59, Delta: -1},
+		},
+	}
+
+	tolerance := config.RegressionTolerance{
+		File:    config.PerOverride{config.StatementsSection: 1},
+		Package: config.PerOverride{config.StatementsSection: 2},
+	}
+	require.True(t, c.ApplyRegressionTolerance(tolerance))
+	require.True(t, c.Failed())
+	require.Equal(t, []Regression{
+		{
+			Scope: config.RegressionScopeFile, Name: "a.go", Metric: config.BlocksSection,
+			Delta: Delta{Old: 80, New: 70, Delta: -10},
+		},
+		{
+			Scope: config.RegressionScopeTotal, Name: "total", Metric: config.BlocksSection,
+			Delta: Delta{Old: 60, New: 59, Delta: -1},
+		},
+	}, c.Regressions)
+
+	tolerance.File[config.BlocksSection] = 10
+	tolerance.Total = config.PerOverride{config.BlocksSection: 5}
+	require.False(t, c.ApplyRegressionTolerance(tolerance))
+	require.Empty(t, c.Regressions)
+	require.False(t, c.Failed())
+}
+
+func TestCompareEntries(t *testing.T) {
+	from := &Entry{
+		Commit: "aaaaaaa1",
+		Branch: "main",
+		Results: compute.Results{
+			ByTotal: compute.Totals{Statements: compute.TotalStatements{Percentage: 40}},
+		},
+	}
+	to := &Entry{
+		Commit:    "bbbbbbb2",
+		Branch:    "release",
+		Tags:      []string{"v1.1.0"},
+		Timestamp: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		Results: compute.Results{
+			ByTotal: compute.Totals{Statements: compute.TotalStatements{Percentage: 45}},
+		},
+	}
+
+	c := CompareEntries("v1.0.0", from, "v1.1.0", to)
+	require.Equal(t, "v1.0.0", c.Ref)
+	require.Equal(t, "aaaaaaa1", c.Commit)
+	require.Equal(t, &Target{
+		Ref:       "v1.1.0",
+		Commit:    "bbbbbbb2",
+		Branch:    "release",
+		Tags:      []string{"v1.1.0"},
+		Timestamp: to.Timestamp,
+	}, c.Target)
+	require.Equal(t, Delta{Old: 40, New: 45, Delta: 5}, c.ByTotal.Statements)
+}
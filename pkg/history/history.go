@@ -2,8 +2,8 @@
 package history
 
 import (
-	"encoding/json"
-	"os"
+	"fmt"
+	"slices"
 	"sort"
 	"time"
 
@@ -21,55 +21,149 @@ var (
 	defaultRepoPath = "."
 )
 
+// Entry keys select which existing entry AddResults replaces.
+const (
+	// KeyCommit keeps one entry per commit.
+	KeyCommit = "commit"
+	// KeyCommitLabel keeps one entry per commit and label, so several suites
+	// can be recorded for the same commit.
+	KeyCommitLabel = "commit+label"
+)
+
+// refLabelSeparator separates the ref from the label of a label-qualified ref
+// such as "abc1234@integration".
+const refLabelSeparator = "@"
+
 // Entry holds details for a single go-covercheck historical outcome.
 type Entry struct {
-	Commit    string          `json:"commit"`
-	Branch    string          `json:"branch"`
-	Tags      []string        `json:"tags,omitempty"`
-	Label     string          `json:"label,omitempty"`
-	Timestamp time.Time       `json:"timestamp"`
-	Results   compute.Results `json:"results"`
+	Commit    string          `json:"commit"           yaml:"commit"`
+	Branch    string          `json:"branch"           yaml:"branch"`
+	Tags      []string        `json:"tags,omitempty"   yaml:"tags,omitempty"`
+	Label     string          `json:"label,omitempty"  yaml:"label,omitempty"`
+	Timestamp time.Time       `json:"timestamp"        yaml:"timestamp"`
+	Build     *Build          `json:"build,omitempty"  yaml:"build,omitempty"`
+	Results   compute.Results `json:"results"          yaml:"results"`
+	Files     []FileLines     `json:"files,omitempty"  yaml:"files,omitempty"`
+}
+
+// Ref returns the label-qualified ref of the entry, see QualifiedRef.
+func (e Entry) Ref() string {
+	return QualifiedRef(e.Commit, e.Label)
+}
+
+// HasLines reports whether the entry holds line coverage, which entries
+// recorded before line coverage was tracked do not. Once loaded, an entry holds
+// line coverage for its totals, files, and packages or for none of them.
+func (e Entry) HasLines() bool {
+	return e.Results.ByTotal.Lines.Coverage != ""
+}
+
+// QualifiedRef returns commit abbreviated to seven characters, followed by
+// "@label" when label is set, such as "abc1234@integration".
+func QualifiedRef(commit, label string) string {
+	if len(commit) > 7 { //nolint:mnd
+		commit = commit[:7]
+	}
+	if label == "" {
+		return commit
+	}
+	return commit + refLabelSeparator + label
 }
 
 // History holds multiple Entry details for go-covercheck historical outcomes.
 type History struct {
-	Entries []Entry `json:"entries"`
-	path    string
+	// Version is the schema version the History was written with, see
+	// SchemaVersion. It is only set in stored history.
+	Version  int     `json:"version,omitempty" yaml:"version,omitempty"`
+	Entries  []Entry `json:"entries"           yaml:"entries"`
+	storage  Storage
+	key      string
+	metadata map[string]string
+	files    []FileLines
 }
 
 // New creates a History collection for the path specified.
 func New(path string) *History {
+	return NewWithStorage(NewFileStorage(path))
+}
+
+// NewWithStorage creates an empty History collection stored in s.
+func NewWithStorage(s Storage) *History {
 	return &History{
-		path: path,
+		storage: s,
 	}
 }
 
 // Load History from a file path.
 func Load(path string) (*History, error) {
-	h := &History{
-		path: path,
-	}
-	b, err := os.ReadFile(h.path)
+	return LoadStorage(NewFileStorage(path))
+}
+
+// LoadStorage loads History from s.
+func LoadStorage(s Storage) (*History, error) {
+	entries, err := s.Load()
 	if err != nil {
 		return nil, err
 	}
+	return &History{Entries: entries, storage: s}, nil
+}
 
-	err = json.Unmarshal(b, h)
+// Open loads History from the Storage of a history URI, see OpenStorage.
+func Open(uri string) (*History, error) {
+	s, err := OpenStorage(uri)
 	if err != nil {
 		return nil, err
 	}
+	return LoadStorage(s)
+}
+
+// SetKey sets the key identifying the entry AddResults replaces, being
+// KeyCommit, the default, or KeyCommitLabel.
+func (h *History) SetKey(key string) error {
+	switch key {
+	case "":
+		h.key = KeyCommit
+	case KeyCommit, KeyCommitLabel:
+		h.key = key
+	default:
+		return fmt.Errorf("invalid history key %q, expected %s or %s", key, KeyCommit, KeyCommitLabel)
+	}
+	return nil
+}
+
+// sameKey reports whether a and b are the same entry under the key of the
+// History.
+func (h *History) sameKey(a, b Entry) bool {
+	if h.key == KeyCommitLabel {
+		return a.Commit == b.Commit && a.Label == b.Label
+	}
+	return a.Commit == b.Commit
+}
 
-	return h, nil
+// SetMetadata sets the user-provided metadata recorded in the Build of the
+// entries AddResults adds.
+func (h *History) SetMetadata(metadata map[string]string) {
+	h.metadata = metadata
 }
 
-// AddResults adds results to the History, optionally with a label.
+// SetFiles sets the line detail of each file recorded in the entries
+// AddResults adds, see CollectFileLines.
+func (h *History) SetFiles(files []FileLines) {
+	h.files = files
+}
+
+// AddResults adds results to the History, optionally with a label, replacing
+// the entry of the same key, see SetKey. The entry records the Build detected
+// from the environment, see DetectBuild.
 func (h *History) AddResults(results compute.Results, label string) {
 	entry := startEntry(label, defaultRepoPath)
+	entry.Build = DetectBuild(h.metadata)
 	entry.Results = results
+	entry.Files = h.files
 
 	updated := false
 	for i, existing := range h.Entries {
-		if existing.Commit == entry.Commit {
+		if h.sameKey(existing, entry) {
 			entry.Timestamp = time.Now().UTC()
 			h.Entries[i] = entry
 			updated = true
@@ -81,93 +175,121 @@ func (h *History) AddResults(results compute.Results, label string) {
 		h.Entries = append(h.Entries, entry)
 	}
 
-	// Sort newest-first by Timestamp
-	sort.SliceStable(h.Entries, func(i, j int) bool {
-		return h.Entries[i].Timestamp.After(h.Entries[j].Timestamp)
+	sortEntries(h.Entries)
+}
+
+// sortEntries sorts entries newest-first by Timestamp.
+func sortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
 	})
 }
 
-// Save results as an Entry to all the History in the file path.
+// Save the History to its Storage, keeping at most limit of the newest
+// entries when limit is greater than 0. Entries saved by another process in
+// the meantime are kept unless they have the key of an entry of the History,
+// see SetKey.
 func (h *History) Save(limit int) error {
-	// limit how much is written
-	if limit > 0 && limit < len(h.Entries) {
-		h.Entries = h.Entries[:limit]
-	}
-
-	b, err := json.MarshalIndent(h, "", "  ")
+	entries, err := h.storage.Save(h.Entries, h.key, limit)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(h.path, b, 0600) //nolint:mnd
+	h.Entries = entries
+	return nil
 }
 
-// FindByRef finds a History Entry that matches the ref string and returns it.
-func (h *History) FindByRef(ref string) *Entry {
-	for _, entry := range h.Entries {
-		if entry.Commit == ref || entry.Commit[:7] == ref ||
-			entry.Branch == ref || entry.Label == ref {
-			return &entry
-		}
-		for _, t := range entry.Tags {
-			if t == ref {
-				return &entry
-			}
-		}
+// FindByRef finds the History Entry that the ref resolves to and returns it,
+// or nil when there is none. The ref may be a commit or a prefix of one, a
+// branch, a tag, a label, or a git revision such as HEAD~1 or origin/main, and
+// may be qualified with a label as "ref@label". A commit prefix matching
+// entries of several commits fails with ErrAmbiguousRef.
+func (h *History) FindByRef(ref string) (*Entry, error) {
+	i, err := h.indexOfRef(ref)
+	if err != nil || i < 0 {
+		return nil, err
 	}
-	return nil
+	entry := h.Entries[i]
+	return &entry, nil
 }
 
-// DeleteByRef deletes a History Entry that matches the ref string and returns true if found and deleted.
-func (h *History) DeleteByRef(ref string) bool {
+// Filter keeps the entries of the History that keep returns true for.
+func (h *History) Filter(keep func(Entry) bool) {
+	h.Entries = slices.DeleteFunc(h.Entries, func(entry Entry) bool {
+		return !keep(entry)
+	})
+}
+
+// LatestOnBranch returns the most recent History Entry recorded on branch, or
+// nil when there is none.
+func (h *History) LatestOnBranch(branch string) *Entry {
+	var latest *Entry
 	for i, entry := range h.Entries {
-		if entry.Commit == ref || (len(entry.Commit) >= 7 && entry.Commit[:7] == ref) ||
-			entry.Branch == ref || entry.Label == ref {
-			// Remove the entry at index i
-			h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
-			return true
-		}
-		for _, t := range entry.Tags {
-			if t == ref {
-				// Remove the entry at index i
-				h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
-				return true
-			}
+		if entry.Branch == branch && (latest == nil || entry.Timestamp.After(latest.Timestamp)) {
+			latest = &h.Entries[i]
 		}
 	}
-	return false
+	return latest
 }
 
-func startEntry(label, repoPath string) Entry {
-	var commit = "unknown"
-	var branch = "unknown"
-	var tags []string
+// DeleteByRef deletes the History Entry that the ref resolves to and returns
+// true if found and deleted. See FindByRef for the refs resolved.
+func (h *History) DeleteByRef(ref string) (bool, error) {
+	i, err := h.indexOfRef(ref)
+	if err != nil || i < 0 {
+		return false, err
+	}
+	h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
+	return true, nil
+}
+
+// GitInfo holds the git details of a repository HEAD.
+type GitInfo struct {
+	Commit string
+	Branch string
+	Tags   []string
+}
+
+// DetectGitInfo returns the commit, branch, and tags of HEAD for the git
+// repository at repoPath. Values are "unknown" when repoPath is not a git
+// repository, and the branch is "detached" when HEAD is not a named branch.
+func DetectGitInfo(repoPath string) GitInfo {
+	info := GitInfo{
+		Commit: "unknown",
+		Branch: "unknown",
+	}
 
 	repo, err := git.PlainOpen(repoPath)
-	if err == nil { //nolint:nestif
-		head, herr := repo.Head()
-		if herr == nil {
-			commit = head.Hash().String()
-
-			// Detect if HEAD is pointing to a named branch
-			branch = "detached"
-			if head.Name().IsBranch() {
-				branch = head.Name().Short()
-			}
-
-			tagIter, _ := repo.Tags()
-			_ = tagIter.ForEach(func(ref *plumbing.Reference) error {
-				if ref.Hash() == head.Hash() {
-					tags = append(tags, ref.Name().Short())
-				}
-				return nil
-			})
-		}
+	if err != nil {
+		return info
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return info
 	}
+	info.Commit = head.Hash().String()
 
+	// Detect if HEAD is pointing to a named branch
+	info.Branch = "detached"
+	if head.Name().IsBranch() {
+		info.Branch = head.Name().Short()
+	}
+
+	tagIter, _ := repo.Tags()
+	_ = tagIter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Hash() == head.Hash() {
+			info.Tags = append(info.Tags, ref.Name().Short())
+		}
+		return nil
+	})
+	return info
+}
+
+func startEntry(label, repoPath string) Entry {
+	info := DetectGitInfo(repoPath)
 	return Entry{
-		Commit:    commit,
-		Branch:    branch,
-		Tags:      tags,
+		Commit:    info.Commit,
+		Branch:    info.Branch,
+		Tags:      info.Tags,
 		Label:     label,
 		Timestamp: time.Now().UTC(),
 	}
@@ -12,6 +12,51 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// entryOption sets a field of the Entry returned by testEntry.
+type entryOption func(*Entry)
+
+// testEntry returns an entry of commit recorded on main with the opts applied.
+func testEntry(commit string, opts ...entryOption) Entry {
+	entry := Entry{Commit: commit, Branch: "main"}
+	for _, opt := range opts {
+		opt(&entry)
+	}
+	return entry
+}
+
+// onDay records the entry at midnight UTC on the day of July 2025.
+func onDay(day int) entryOption {
+	return at(time.Date(2025, 7, day, 0, 0, 0, 0, time.UTC))
+}
+
+// at records the entry at timestamp.
+func at(timestamp time.Time) entryOption {
+	return func(e *Entry) {
+		e.Timestamp = timestamp
+	}
+}
+
+// onBranch records the entry on branch.
+func onBranch(branch string) entryOption {
+	return func(e *Entry) {
+		e.Branch = branch
+	}
+}
+
+// withLabel sets the label of the entry.
+func withLabel(label string) entryOption {
+	return func(e *Entry) {
+		e.Label = label
+	}
+}
+
+// withResults sets the results of the entry.
+func withResults(results compute.Results) entryOption {
+	return func(e *Entry) {
+		e.Results = results
+	}
+}
+
 func TestLoad(t *testing.T) {
 	path := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
 	h, err := Load(path)
@@ -37,7 +82,7 @@ func TestLoad_NonExistentFile(t *testing.T) {
 func TestNew(t *testing.T) {
 	h := New("")
 	require.NotNil(t, h)
-	require.Empty(t, h.path)
+	require.Equal(t, &fileStorage{path: ""}, h.storage)
 }
 
 func TestHistory_AddResults(t *testing.T) {
@@ -196,7 +241,7 @@ func TestFindsEntryByCommit(t *testing.T) {
 	h.AddResults(compute.Results{}, "label1")
 	h.Entries[0].Commit = "commit123"
 
-	entry := h.FindByRef("commit123")
+	entry := findByRef(t, h, "commit123")
 	require.NotNil(t, entry)
 	require.Equal(t, "commit123", entry.Commit)
 }
@@ -208,7 +253,7 @@ func TestFindsEntryByBranch(t *testing.T) {
 	h.AddResults(compute.Results{}, "label1")
 	h.Entries[0].Branch = "main"
 
-	entry := h.FindByRef("main")
+	entry := findByRef(t, h, "main")
 	require.NotNil(t, entry)
 	require.Equal(t, "main", entry.Branch)
 }
@@ -219,7 +264,7 @@ func TestFindsEntryByLabel(t *testing.T) {
 
 	h.AddResults(compute.Results{}, "label1")
 
-	entry := h.FindByRef("label1")
+	entry := findByRef(t, h, "label1")
 	require.NotNil(t, entry)
 	require.Equal(t, "label1", entry.Label)
 }
@@ -231,18 +276,93 @@ func TestFindsEntryByTag(t *testing.T) {
 	h.AddResults(compute.Results{}, "label1")
 	h.Entries[0].Tags = []string{"v1.0.0"}
 
-	entry := h.FindByRef("v1.0.0")
+	entry := findByRef(t, h, "v1.0.0")
 	require.NotNil(t, entry)
 	require.Contains(t, entry.Tags, "v1.0.0")
 }
 
+func TestHistory_AddResults_KeyCommitLabel(t *testing.T) {
+	r1 := compute.Results{ByTotal: compute.Totals{Statements: compute.TotalStatements{Coverage: "1/1"}}}
+	r2 := compute.Results{ByTotal: compute.Totals{Statements: compute.TotalStatements{Coverage: "5/5"}}}
+
+	h := New("")
+	require.NoError(t, h.SetKey(KeyCommitLabel))
+	h.AddResults(r1, "unit")
+	h.AddResults(r1, "integration")
+	h.AddResults(r2, "unit")
+
+	require.Len(t, h.Entries, 2)
+	require.Equal(t, r2, findByRef(t, h, "unknown@unit").Results)
+	require.Equal(t, r1, findByRef(t, h, "unknown@integration").Results)
+}
+
+func TestHistory_SetKey(t *testing.T) {
+	h := New("")
+	require.NoError(t, h.SetKey(""))
+	require.Equal(t, KeyCommit, h.key)
+	require.ErrorContains(t, h.SetKey("label"), `invalid history key "label"`)
+}
+
+func TestFindsEntryByQualifiedRef(t *testing.T) {
+	h := &History{Entries: []Entry{
+		{Commit: "abc1234def", Branch: "main", Tags: []string{"v1.0.0"}, Label: "unit"},
+		{Commit: "abc1234def", Branch: "main", Tags: []string{"v1.0.0"}, Label: "integration"},
+		{Commit: "fed4321cba", Branch: "main", Label: "team@example"},
+	}}
+
+	for ref, label := range map[string]string{
+		"main":                 "unit",
+		"abc1234@integration":  "integration",
+		"abc1234def@unit":      "unit",
+		"main@integration":     "integration",
+		"v1.0.0@integration":   "integration",
+		"team@example":         "team@example",
+		"fed4321@team@example": "team@example",
+	} {
+		entry := findByRef(t, h, ref)
+		require.NotNil(t, entry, ref)
+		require.Equal(t, label, entry.Label, ref)
+	}
+	require.Nil(t, findByRef(t, h, "abc1234@e2e"))
+	for _, ref := range []string{"abc1234", "abc1234def", "v1.0.0"} {
+		_, err := h.FindByRef(ref)
+		require.ErrorIs(t, err, ErrAmbiguousRef, ref)
+	}
+
+	require.True(t, deleteByRef(t, h, "abc1234@integration"))
+	require.Len(t, h.Entries, 2)
+	require.Nil(t, findByRef(t, h, "abc1234@integration"))
+	require.NotNil(t, findByRef(t, h, "abc1234@unit"))
+	require.Equal(t, "unit", findByRef(t, h, "abc1234").Label)
+}
+
+func TestEntry_Ref(t *testing.T) {
+	require.Equal(t, "abc1234", Entry{Commit: "abc1234def"}.Ref())
+	require.Equal(t, "abc1234@integration", Entry{Commit: "abc1234def", Label: "integration"}.Ref())
+	require.Equal(t, "unknown@unit", Entry{Commit: "unknown", Label: "unit"}.Ref())
+}
+
+func TestHistory_LatestOnBranch(t *testing.T) {
+	now := time.Now()
+	h := &History{Entries: []Entry{
+		{Commit: "c1", Branch: "feature", Timestamp: now},
+		{Commit: "c2", Branch: "main", Timestamp: now.Add(-time.Hour)},
+		{Commit: "c3", Branch: "main", Timestamp: now.Add(-time.Minute)},
+	}}
+
+	entry := h.LatestOnBranch("main")
+	require.NotNil(t, entry)
+	require.Equal(t, "c3", entry.Commit)
+	require.Nil(t, h.LatestOnBranch("release"))
+}
+
 func TestReturnsNilForNonexistentRef(t *testing.T) {
 	h := New("")
 	require.NotNil(t, h)
 
 	h.AddResults(compute.Results{}, "label1")
 
-	entry := h.FindByRef("nonexistent")
+	entry := findByRef(t, h, "nonexistent")
 	require.Nil(t, entry)
 }
 
@@ -370,7 +490,7 @@ func TestHistory_DeleteByRef(t *testing.T) {
 	require.Len(t, h.Entries, 1)
 
 	// Test delete by commit
-	deleted := h.DeleteByRef("commit123")
+	deleted := deleteByRef(t, h, "commit123")
 	require.True(t, deleted)
 	require.Empty(t, h.Entries)
 
@@ -382,7 +502,7 @@ func TestHistory_DeleteByRef(t *testing.T) {
 	}, "label2")
 	h.Entries[0].Branch = "feature"
 
-	deleted = h.DeleteByRef("feature")
+	deleted = deleteByRef(t, h, "feature")
 	require.True(t, deleted)
 	require.Empty(t, h.Entries)
 
@@ -394,7 +514,7 @@ func TestHistory_DeleteByRef(t *testing.T) {
 	}, "label3")
 	h.Entries[0].Tags = []string{"v2.0.0"}
 
-	deleted = h.DeleteByRef("v2.0.0")
+	deleted = deleteByRef(t, h, "v2.0.0")
 	require.True(t, deleted)
 	require.Empty(t, h.Entries)
 
@@ -405,7 +525,7 @@ func TestHistory_DeleteByRef(t *testing.T) {
 		},
 	}, "label4")
 
-	deleted = h.DeleteByRef("label4")
+	deleted = deleteByRef(t, h, "label4")
 	require.True(t, deleted)
 	require.Empty(t, h.Entries)
 }
@@ -418,7 +538,7 @@ func TestHistory_DeleteByRef_ShortCommit(t *testing.T) {
 	h.Entries[0].Commit = "commit123456789"
 
 	// Test delete by short commit (7 chars)
-	deleted := h.DeleteByRef("commit1")
+	deleted := deleteByRef(t, h, "commit1")
 	require.True(t, deleted)
 	require.Empty(t, h.Entries)
 }
@@ -430,7 +550,7 @@ func TestHistory_DeleteByRef_NonExistent(t *testing.T) {
 	h.AddResults(compute.Results{}, "label1")
 
 	// Test delete by non-existent ref
-	deleted := h.DeleteByRef("nonexistent")
+	deleted := deleteByRef(t, h, "nonexistent")
 	require.False(t, deleted)
 	require.Len(t, h.Entries, 1)
 }
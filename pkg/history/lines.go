@@ -0,0 +1,329 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/format/diff"
+	"github.com/mach6/go-covercheck/pkg/gitdiff"
+	"github.com/mach6/go-covercheck/pkg/lines"
+	"golang.org/x/tools/cover"
+)
+
+// blockHashBytes is the number of sha256 bytes kept in a block hash.
+const blockHashBytes = 8
+
+// FileLines holds the line detail of a file recorded in an Entry, so the lines
+// that lost or gained coverage since another entry can be found, see
+// CompareLines.
+type FileLines struct {
+	File string `json:"file"                yaml:"file"`
+	// Uncovered lists the uncovered line ranges, such as "3-5,9".
+	Uncovered string `json:"uncovered,omitempty" yaml:"uncovered,omitempty"`
+	// Covered holds the content hashes of the covered blocks, being a hash
+	// of their executable lines that ignores indentation.
+	Covered []string `json:"covered,omitempty"   yaml:"covered,omitempty"`
+	// uncoveredHashes holds the content hash of the uncovered block of each
+	// uncovered line. It is only known for current results, never stored.
+	uncoveredHashes map[int]string
+}
+
+// LineChange lists the line ranges of a file that lost or gained coverage,
+// numbered as in the compared results.
+type LineChange struct {
+	File           string `json:"file"                     yaml:"file"`
+	NewlyUncovered string `json:"newlyUncovered,omitempty" yaml:"newlyUncovered,omitempty"`
+	NewlyCovered   string `json:"newlyCovered,omitempty"   yaml:"newlyCovered,omitempty"`
+}
+
+// CollectFileLines returns the FileLines of each profile, reading the source
+// files to hash the covered blocks. Blocks whose source cannot be read are not
+// hashed.
+func CollectFileLines(profiles []*cover.Profile) []FileLines {
+	files := make([]FileLines, 0, len(profiles))
+	for _, p := range profiles {
+		blocks := lines.CollectBlocks(p)
+		f := FileLines{
+			File:            p.FileName,
+			Uncovered:       lines.FormatUncoveredFromBlocks(blocks),
+			uncoveredHashes: map[int]string{},
+		}
+		for _, block := range blocks {
+			hash := blockHash(block)
+			if hash == "" {
+				continue
+			}
+			if block.IsCovered() {
+				f.Covered = append(f.Covered, hash)
+				continue
+			}
+			for _, l := range block.Lines {
+				if !l.IsFiltered {
+					f.uncoveredHashes[l.LineNumber] = hash
+				}
+			}
+		}
+		slices.Sort(f.Covered)
+		f.Covered = slices.Compact(f.Covered)
+		files = append(files, f)
+	}
+	return files
+}
+
+// blockHash returns the content hash of the executable lines of block, or ""
+// when its source is unavailable.
+func blockHash(block lines.Block) string {
+	var content []string
+	for _, l := range block.Lines {
+		if l.IsFiltered {
+			continue
+		}
+		trimmed := strings.TrimSpace(l.Content)
+		if trimmed == "" {
+			return ""
+		}
+		content = append(content, trimmed)
+	}
+	if len(content) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(content, "\n")))
+	return hex.EncodeToString(sum[:blockHashBytes])
+}
+
+// CompareLines returns the LineChange of each file recorded in both from and
+// to whose lines lost or gained coverage. Lines are mapped from the fromCommit
+// to the toCommit, or HEAD when toCommit is empty, through the git diff of the
+// two commits, so only unchanged lines are compared. Uncovered lines the diff
+// does not map, such as code that moved, are newly uncovered when their block
+// was covered in from, which requires the block hashes of CollectFileLines.
+// Without the git repository or either commit only such moved code is found.
+func CompareLines(fromCommit string, from []FileLines, toCommit string, to []FileLines) []LineChange {
+	mappings, err := diffLineMappings(defaultRepoPath, fromCommit, toCommit)
+	mapped := err == nil
+
+	fromFiles := make(map[string]FileLines, len(from))
+	for _, f := range from {
+		fromFiles[f.File] = f
+	}
+	changes := []LineChange{}
+	for _, curr := range to {
+		prev, ok := fromFiles[curr.File]
+		if !ok {
+			continue
+		}
+		var m *lineMapping
+		if mapped {
+			m = mappings[curr.File]
+		}
+		c := compareFileLines(prev, curr, m, mapped)
+		if c.NewlyUncovered != "" || c.NewlyCovered != "" {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// compareFileLines compares the lines of a file mapped by m, when mapped.
+func compareFileLines(prev, curr FileLines, m *lineMapping, mapped bool) LineChange {
+	prevUncovered := lineSet(prev.Uncovered)
+	currUncovered := lineSet(curr.Uncovered)
+	prevCovered := make(map[string]bool, len(prev.Covered))
+	for _, hash := range prev.Covered {
+		prevCovered[hash] = true
+	}
+
+	var newlyUncovered, newlyCovered []int
+	for ln := range currUncovered {
+		if mapped {
+			if old, ok := m.toOld(ln); ok {
+				if !prevUncovered[old] {
+					newlyUncovered = append(newlyUncovered, ln)
+				}
+				continue
+			}
+		}
+		if hash, ok := curr.uncoveredHashes[ln]; ok && prevCovered[hash] {
+			newlyUncovered = append(newlyUncovered, ln)
+		}
+	}
+	if mapped {
+		for old := range prevUncovered {
+			if ln, ok := m.toNew(old); ok && !currUncovered[ln] {
+				newlyCovered = append(newlyCovered, ln)
+			}
+		}
+	}
+
+	slices.Sort(newlyUncovered)
+	slices.Sort(newlyCovered)
+	return LineChange{
+		File:           curr.File,
+		NewlyUncovered: lines.FormatLineRanges(newlyUncovered),
+		NewlyCovered:   lines.FormatLineRanges(newlyCovered),
+	}
+}
+
+// lineSet returns the set of lines of ranges, ignoring malformed ranges.
+func lineSet(ranges string) map[int]bool {
+	parsed, _ := lines.ParseLineRanges(ranges)
+	set := make(map[int]bool, len(parsed))
+	for _, ln := range parsed {
+		set[ln] = true
+	}
+	return set
+}
+
+// lineMapping maps the unchanged lines of a file between two commits. A nil
+// lineMapping maps each line to itself, being a file without changes.
+type lineMapping struct {
+	forward  map[int]int
+	backward map[int]int
+}
+
+func (m *lineMapping) toNew(line int) (int, bool) {
+	if m == nil {
+		return line, true
+	}
+	ln, ok := m.forward[line]
+	return ln, ok
+}
+
+func (m *lineMapping) toOld(line int) (int, bool) {
+	if m == nil {
+		return line, true
+	}
+	ln, ok := m.backward[line]
+	return ln, ok
+}
+
+// lineMappings holds the lineMapping of each changed file by its path relative
+// to the module.
+type lineMappings map[string]*lineMapping
+
+// diffLineMappings returns the lineMappings of the files changed between the
+// fromCommit and the toCommit, or HEAD when toCommit is empty, in the
+// repository holding the module at repoPath. Files outside the module are
+// left out.
+func diffLineMappings(repoPath, fromCommit, toCommit string) (lineMappings, error) {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", repoPath, err)
+	}
+	moduleRoot, err := moduleRootPath(repo, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if toCommit == "" {
+		toCommit = plumbing.HEAD.String()
+	}
+	fromHash, err := gitdiff.ResolveReference(repo, fromCommit)
+	if err != nil {
+		return nil, err
+	}
+	toHash, err := gitdiff.ResolveReference(repo, toCommit)
+	if err != nil {
+		return nil, err
+	}
+	mappings := lineMappings{}
+	if fromHash == toHash {
+		return mappings, nil
+	}
+
+	from, err := repo.CommitObject(fromHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", fromHash, err)
+	}
+	to, err := repo.CommitObject(toHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", toHash, err)
+	}
+	patch, err := from.Patch(to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patch: %w", err)
+	}
+	for _, filePatch := range patch.FilePatches() {
+		fromFile, toFile := filePatch.Files()
+		m := &lineMapping{forward: map[int]int{}, backward: map[int]int{}}
+		if fromFile != nil && toFile != nil {
+			mapChunks(m, filePatch.Chunks())
+		}
+		// a file added or removed has no unchanged lines
+		for _, f := range []diff.File{fromFile, toFile} {
+			if f == nil {
+				continue
+			}
+			if file, ok := moduleRelative(moduleRoot, f.Path()); ok {
+				mappings[file] = m
+			}
+		}
+	}
+	return mappings, nil
+}
+
+// moduleRootPath returns the slash separated path of the module at repoPath
+// relative to the worktree root of repo, or "" when it is the root.
+func moduleRootPath(repo *git.Repository, repoPath string) (string, error) {
+	w, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	root, err := filepath.EvalSymlinks(w.Filesystem.Root())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve worktree root: %w", err)
+	}
+	module, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve module path %s: %w", repoPath, err)
+	}
+	if module, err = filepath.EvalSymlinks(module); err != nil {
+		return "", fmt.Errorf("failed to resolve module path %s: %w", repoPath, err)
+	}
+	rel, err := filepath.Rel(root, module)
+	if err != nil {
+		return "", fmt.Errorf("failed to find module path %s in the worktree: %w", repoPath, err)
+	}
+	if rel = filepath.ToSlash(rel); rel == "." {
+		return "", nil
+	}
+	return rel, nil
+}
+
+// moduleRelative returns file, a path in the repository, relative to the
+// moduleRoot, and whether the file is in the module.
+func moduleRelative(moduleRoot, file string) (string, bool) {
+	if moduleRoot == "" {
+		return file, true
+	}
+	return strings.CutPrefix(file, moduleRoot+"/")
+}
+
+// mapChunks adds the lines of the unchanged chunks to m.
+func mapChunks(m *lineMapping, chunks []diff.Chunk) {
+	oldLine, newLine := 1, 1
+	for _, chunk := range chunks {
+		n := strings.Count(chunk.Content(), "\n")
+		if content := chunk.Content(); content != "" && !strings.HasSuffix(content, "\n") {
+			n++
+		}
+		switch chunk.Type() {
+		case diff.Equal:
+			for i := range n {
+				m.forward[oldLine+i] = newLine + i
+				m.backward[newLine+i] = oldLine + i
+			}
+			oldLine += n
+			newLine += n
+		case diff.Delete:
+			oldLine += n
+		case diff.Add:
+			newLine += n
+		}
+	}
+}
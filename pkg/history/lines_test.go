@@ -0,0 +1,143 @@
+package history //nolint:testpackage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/cover"
+)
+
+const calcSource = `package calc
+
+func Add(a, b int) int {
+	return a + b
+}
+
+func Sub(a, b int) int {
+	return a - b
+}
+`
+
+// calcSourceDocumented is calcSource with two lines inserted before Add.
+const calcSourceDocumented = `package calc
+
+// Add adds a and b.
+// Sub subtracts them.
+func Add(a, b int) int {
+	return a + b
+}
+
+func Sub(a, b int) int {
+	return a - b
+}
+`
+
+// calcProfile returns the profile of calcSource at fileName with Add and Sub
+// executed the given number of times.
+func calcProfile(fileName string, add, sub int) *cover.Profile {
+	return &cover.Profile{FileName: fileName, Mode: "set", Blocks: []cover.ProfileBlock{
+		{StartLine: 3, StartCol: 24, EndLine: 5, EndCol: 2, NumStmt: 1, Count: add},
+		{StartLine: 7, StartCol: 24, EndLine: 9, EndCol: 2, NumStmt: 1, Count: sub},
+	}}
+}
+
+// commitFile writes content to path in the worktree of repo and commits it.
+func commitFile(t *testing.T, repo *git.Repository, path, content string) string {
+	t.Helper()
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(w.Filesystem.Root(), path)), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(w.Filesystem.Root(), path), []byte(content), 0o600))
+	_, err = w.Add(path)
+	require.NoError(t, err)
+	hash, err := w.Commit("commit "+path, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+func TestCollectFileLines(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "calc.go")
+	require.NoError(t, os.WriteFile(fileName, []byte(calcSource), 0o600))
+
+	files := CollectFileLines([]*cover.Profile{calcProfile(fileName, 1, 0)})
+	require.Len(t, files, 1)
+	require.Equal(t, fileName, files[0].File)
+	require.Equal(t, "7-8", files[0].Uncovered)
+	require.Len(t, files[0].Covered, 1)
+	require.Len(t, files[0].Covered[0], 2*blockHashBytes)
+	require.Len(t, files[0].uncoveredHashes, 2)
+	require.Equal(t, files[0].uncoveredHashes[7], files[0].uncoveredHashes[8])
+
+	// the hash of Sub is the same once covered
+	covered := CollectFileLines([]*cover.Profile{calcProfile(fileName, 1, 1)})
+	require.Empty(t, covered[0].Uncovered)
+	require.Contains(t, covered[0].Covered, files[0].uncoveredHashes[8])
+
+	// unreadable sources are not hashed
+	missing := CollectFileLines([]*cover.Profile{calcProfile("no/such/calc.go", 1, 0)})
+	require.Equal(t, "7-9", missing[0].Uncovered)
+	require.Empty(t, missing[0].Covered)
+	require.Empty(t, missing[0].uncoveredHashes)
+}
+
+func TestCompareLines_GitDiff(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	commitFile(t, repo, "cmd/calc/calc.go", calcSource)
+	c0 := commitFile(t, repo, "pkg/calc/calc.go", calcSource)
+	c1 := commitFile(t, repo, "pkg/calc/calc.go", calcSourceDocumented)
+	c2 := commitFile(t, repo, "README.md", "# calc\n")
+	defaultRepoPath = repoDir
+	defer func() {
+		defaultRepoPath = "."
+	}()
+
+	// Sub is uncovered at c0, and Add at c1 where both moved down two lines
+	from := []FileLines{{File: "pkg/calc/calc.go", Uncovered: "8"}}
+	to := []FileLines{{File: "pkg/calc/calc.go", Uncovered: "6"}}
+	want := []LineChange{{File: "pkg/calc/calc.go", NewlyUncovered: "6", NewlyCovered: "10"}}
+	require.Equal(t, want, CompareLines(c0, from, c1, to))
+
+	// files are matched by their path relative to the module at pkg, and not
+	// by the cmd/calc/calc.go that is unchanged
+	defaultRepoPath = filepath.Join(repoDir, "pkg")
+	from[0].File, to[0].File = "calc/calc.go", "calc/calc.go"
+	want[0].File = "calc/calc.go"
+	require.Equal(t, want, CompareLines(c0, from, c1, to))
+
+	// the module at cmd holds the calc/calc.go without changes
+	defaultRepoPath = filepath.Join(repoDir, "cmd")
+	require.Equal(t, []LineChange{{File: "calc/calc.go", NewlyUncovered: "6", NewlyCovered: "8"}},
+		CompareLines(c0, from, c1, to))
+	defaultRepoPath = filepath.Join(repoDir, "pkg")
+
+	// unchanged files map each line to itself, and HEAD is c2
+	require.Equal(t, []LineChange{{File: "calc/calc.go", NewlyUncovered: "8", NewlyCovered: "6"}},
+		CompareLines(c1, to, "", from))
+	require.Equal(t, []LineChange{}, CompareLines(c1, to, c2, to))
+}
+
+func TestCompareLines_MovedBlock(t *testing.T) {
+	defaultRepoPath = t.TempDir()
+	defer func() {
+		defaultRepoPath = "."
+	}()
+
+	from := []FileLines{{File: "calc.go", Uncovered: "3", Covered: []string{"aaaa", "bbbb"}}}
+	to := []FileLines{{File: "calc.go", Uncovered: "20-21,30", uncoveredHashes: map[int]string{
+		20: "bbbb", 21: "bbbb", 30: "cccc",
+	}}}
+
+	// without the repository only covered blocks that lost coverage are found
+	require.Equal(t, []LineChange{{File: "calc.go", NewlyUncovered: "20-21"}},
+		CompareLines("c0", from, "c1", to))
+	require.Equal(t, []LineChange{}, CompareLines("c0", from, "c1", []FileLines{{File: "other.go", Uncovered: "1"}}))
+}
@@ -0,0 +1,55 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// lockFile takes an exclusive advisory lock on the file at path, creating it
+// when missing, and blocks until the lock is available. The lock is released
+// by the returned function. The lock file is left in place, since removing it
+// would let a process still waiting on the old file race one locking a new
+// file.
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600) //nolint:gosec,mnd
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = unlock(f)
+		_ = f.Close()
+	}, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// over path, so readers see either the old or the new contents and never a
+// partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil { //nolint:mnd
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
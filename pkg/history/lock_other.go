@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package history
+
+import "os"
+
+// lock is a no-op on platforms without advisory file locks; writes remain
+// atomic but concurrent saves may drop entries.
+func lock(*os.File) error {
+	return nil
+}
+
+func unlock(*os.File) error {
+	return nil
+}
@@ -0,0 +1,65 @@
+package history
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Merge conflict policies deciding what Merge does with an entry of the same
+// commit and label as an entry of the History.
+const (
+	// MergeNewest keeps the entry with the newest timestamp.
+	MergeNewest = "newest"
+	// MergeKeepBoth keeps both entries.
+	MergeKeepBoth = "keep-both"
+)
+
+// Merge adds entries to the History, such as those of a history file saved by
+// another CI shard, and returns how many were added or replaced. Identical
+// entries are added once; entries of the same commit and label as an entry of
+// the History are resolved by policy, being MergeNewest, the default, or
+// MergeKeepBoth. MergeKeepBoth fails when the Storage of the History holds a
+// single entry per commit and label, such as that of "dir://", which would
+// replace one entry with the other. The entries are sorted newest first.
+func (h *History) Merge(entries []Entry, policy string) (int, error) {
+	if policy == "" {
+		policy = MergeNewest
+	}
+	if policy != MergeNewest && policy != MergeKeepBoth {
+		return 0, fmt.Errorf("invalid history merge policy %q, expected %s or %s", policy, MergeNewest, MergeKeepBoth)
+	}
+
+	merged := 0
+	for _, entry := range entries {
+		if slices.ContainsFunc(h.Entries, func(e Entry) bool { return reflect.DeepEqual(e, entry) }) {
+			continue
+		}
+		i := slices.IndexFunc(h.Entries, func(e Entry) bool { return idOf(e, KeyCommitLabel) == idOf(entry, KeyCommitLabel) })
+		if i >= 0 && policy == MergeKeepBoth {
+			if _, ok := h.storage.(singleEntryStorage); ok {
+				return merged, fmt.Errorf("cannot keep both history entries of %s, the history storage holds "+
+					"a single entry per commit and label", entry.Ref())
+			}
+		}
+		switch {
+		case i < 0 || policy == MergeKeepBoth:
+			h.Entries = append(h.Entries, entry)
+		case entry.Timestamp.After(h.Entries[i].Timestamp):
+			h.Entries[i] = entry
+		default:
+			continue
+		}
+		merged++
+	}
+
+	sortEntries(h.Entries)
+	return merged, nil
+}
+
+// singleEntryStorage is implemented by a Storage that holds a single entry per
+// commit and label, so cannot keep both entries of a merge conflict.
+type singleEntryStorage interface {
+	Storage
+	singleEntryPerID()
+}
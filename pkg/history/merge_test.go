@@ -0,0 +1,86 @@
+package history //nolint:testpackage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_Merge(t *testing.T) {
+	older := testEntry("aaa", withLabel("unit"), onDay(1))
+	newer := testEntry("aaa", withLabel("unit"), onDay(3), onBranch("shard-2"))
+	other := testEntry("bbb", onDay(2))
+
+	tests := map[string]struct {
+		policy  string
+		merged  int
+		entries []Entry
+	}{
+		"newest": {
+			policy:  MergeNewest,
+			merged:  2,
+			entries: []Entry{newer, other},
+		},
+		"default": {
+			merged:  2,
+			entries: []Entry{newer, other},
+		},
+		"keep both": {
+			policy:  MergeKeepBoth,
+			merged:  2,
+			entries: []Entry{newer, other, older},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := &History{Entries: []Entry{older}}
+			merged, err := h.Merge([]Entry{other, newer, older, other}, tc.policy)
+			require.NoError(t, err)
+			require.Equal(t, tc.merged, merged)
+			require.Equal(t, tc.entries, h.Entries)
+		})
+	}
+}
+
+func TestHistory_Merge_KeepsNewerEntry(t *testing.T) {
+	newer := testEntry("aaa", withLabel("unit"), onDay(3))
+	h := &History{Entries: []Entry{newer}}
+	merged, err := h.Merge([]Entry{testEntry("aaa", withLabel("unit"), onDay(1))}, MergeNewest)
+	require.NoError(t, err)
+	require.Zero(t, merged)
+	require.Equal(t, []Entry{newer}, h.Entries)
+}
+
+func TestHistory_Merge_InvalidPolicy(t *testing.T) {
+	_, err := (&History{}).Merge(nil, "oldest")
+	require.ErrorContains(t, err, `invalid history merge policy "oldest"`)
+}
+
+func TestHistory_Merge_DirStorage(t *testing.T) {
+	older := testEntry("aaa", withLabel("unit"), onDay(1))
+	newer := testEntry("aaa", withLabel("unit"), onDay(3), onBranch("shard-2"))
+	other := testEntry("bbb", onDay(2))
+
+	s := NewDirStorage(t.TempDir())
+	h := NewWithStorage(s)
+	h.Entries = []Entry{older}
+	require.NoError(t, h.Save(0))
+
+	// the files of the dir storage are named by commit and label
+	merged, err := h.Merge([]Entry{other, newer}, MergeKeepBoth)
+	require.ErrorContains(t, err, "cannot keep both history entries of aaa@unit")
+	require.Equal(t, 1, merged)
+
+	h, err = LoadStorage(s)
+	require.NoError(t, err)
+	merged, err = h.Merge([]Entry{other, newer}, MergeNewest)
+	require.NoError(t, err)
+	require.Equal(t, 2, merged)
+	require.NoError(t, h.Save(0))
+
+	h, err = LoadStorage(s)
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 2)
+	require.Equal(t, "shard-2", h.Entries[0].Branch)
+	require.Equal(t, "bbb", h.Entries[1].Commit)
+}
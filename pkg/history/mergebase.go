@@ -0,0 +1,97 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/mach6/go-covercheck/pkg/gitdiff"
+)
+
+// maxFirstParentDepth is the number of first-parent ancestors of a merge-base
+// walked to find one that has an entry.
+//
+// Defined as a package variable to allow testing with a smaller depth.
+var maxFirstParentDepth = 1000
+
+// FindByMergeBase finds the History Entry of the merge-base of HEAD and the
+// target ref, being the commit HEAD forked from, and returns it. When the
+// merge-base has no entry, up to maxFirstParentDepth of its first-parent
+// ancestors are walked to the nearest commit that has one. It returns nil when
+// there is none, including when the walk reaches the end of a shallow clone. The target
+// may be qualified with a label as "ref@label" to only consider entries of
+// that label.
+func (h *History) FindByMergeBase(target string) (*Entry, error) {
+	repo, err := git.PlainOpen(defaultRepoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", defaultRepoPath, err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	var resolveErr error
+	for _, r := range h.labeledRefs(target) {
+		hash, err := gitdiff.ResolveReference(repo, r.ref)
+		if err != nil {
+			resolveErr = err
+			continue
+		}
+		targetCommit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get target commit: %w", err)
+		}
+		bases, err := headCommit.MergeBase(targetCommit)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil, fmt.Errorf("failed to find the merge-base of HEAD and %s, "+
+				"fetch more history into a shallow clone: %w", target, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the merge-base of HEAD and %s: %w", target, err)
+		}
+		if len(bases) == 0 {
+			return nil, fmt.Errorf("HEAD and %s have no merge-base", target)
+		}
+		return h.nearestFirstParentEntry(bases[0], r)
+	}
+	return nil, fmt.Errorf("failed to resolve target reference %q: %w", target, resolveErr)
+}
+
+// nearestFirstParentEntry returns the first entry selected by r of commit or
+// of its nearest first-parent ancestor that has one, or nil when there is
+// none within maxFirstParentDepth ancestors or before a parent missing from a
+// shallow clone.
+func (h *History) nearestFirstParentEntry(commit *object.Commit, r labeledRef) (*Entry, error) {
+	byCommit := map[string]int{}
+	for i, entry := range h.Entries {
+		if _, found := byCommit[entry.Commit]; !found && r.selects(entry) {
+			byCommit[entry.Commit] = i
+		}
+	}
+
+	for range maxFirstParentDepth + 1 {
+		if i, found := byCommit[commit.Hash.String()]; found {
+			entry := h.Entries[i]
+			return &entry, nil
+		}
+		if commit.NumParents() == 0 {
+			break
+		}
+		parent, err := commit.Parent(0)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the parent of commit %s: %w", commit.Hash, err)
+		}
+		commit = parent
+	}
+	return nil, nil //nolint:nilnil // no ancestor has an entry
+}
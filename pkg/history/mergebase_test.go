@@ -0,0 +1,134 @@
+package history //nolint:testpackage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// initForkedRepo creates a git repository where a feature branch forks from
+// main and both branches move on, checked out on feature. It returns its path
+// and the commit hashes in the order c0 and c1 on main, c2 and c3 on feature,
+// and c4 on main.
+func initForkedRepo(t *testing.T) (string, []string) {
+	t.Helper()
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+
+	var hashes []string
+	commit := func() {
+		hash, err := w.Commit("commit", &git.CommitOptions{
+			AllowEmptyCommits: true,
+			Author: &object.Signature{
+				Name:  "Test",
+				Email: "test@example.com",
+				When:  time.Now().Add(time.Duration(len(hashes)) * time.Second),
+			},
+		})
+		require.NoError(t, err)
+		hashes = append(hashes, hash.String())
+	}
+	checkout := func(branch string, create bool) {
+		require.NoError(t, w.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(branch),
+			Create: create,
+		}))
+	}
+
+	commit()
+	commit()
+	head, err := repo.Head()
+	require.NoError(t, err)
+	main := head.Name().Short()
+	checkout("feature", true)
+	commit()
+	commit()
+	checkout(main, false)
+	commit()
+	checkout("feature", false)
+	return repoDir, hashes
+}
+
+func TestHistory_FindByMergeBase(t *testing.T) {
+	repoDir, commits := initForkedRepo(t)
+	defaultRepoPath = repoDir
+	defer func() {
+		defaultRepoPath = "."
+	}()
+
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, commits[3], head.Hash().String())
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName("target"), plumbing.NewHash(commits[4]))))
+
+	h := &History{Entries: []Entry{
+		{Commit: commits[4], Branch: "target"},
+		{Commit: commits[2], Branch: "feature"},
+		{Commit: commits[1], Branch: "target", Label: "unit"},
+		{Commit: commits[0], Branch: "target", Label: "e2e"},
+	}}
+
+	entry, err := h.FindByMergeBase("target")
+	require.NoError(t, err)
+	require.Equal(t, commits[1], entry.Commit)
+
+	entry, err = h.FindByMergeBase("target@e2e")
+	require.NoError(t, err)
+	require.Equal(t, commits[0], entry.Commit)
+
+	h.Entries = h.Entries[:2]
+	entry, err = h.FindByMergeBase("target")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+
+	_, err = h.FindByMergeBase("no-such-branch")
+	require.ErrorContains(t, err, `failed to resolve target reference "no-such-branch"`)
+}
+
+func TestHistory_FindByMergeBase_BoundedWalk(t *testing.T) {
+	repoDir, commits := initForkedRepo(t)
+	defaultRepoPath = repoDir
+	defer func() {
+		defaultRepoPath = "."
+		maxFirstParentDepth = 1000
+	}()
+
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName("target"), plumbing.NewHash(commits[4]))))
+	h := &History{Entries: []Entry{{Commit: commits[0], Branch: "target"}}}
+
+	// the entry is one first-parent ancestor beyond the merge-base
+	entry, err := h.FindByMergeBase("target")
+	require.NoError(t, err)
+	require.Equal(t, commits[0], entry.Commit)
+	maxFirstParentDepth = 0
+	entry, err = h.FindByMergeBase("target")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+
+	// a parent missing from a shallow clone ends the walk
+	maxFirstParentDepth = 1000
+	require.NoError(t, os.Remove(filepath.Join(repoDir, ".git", "objects", commits[0][:2], commits[0][2:])))
+	base, err := repo.CommitObject(plumbing.NewHash(commits[1]))
+	require.NoError(t, err)
+	entry, err = h.nearestFirstParentEntry(base, labeledRef{ref: "target"})
+	require.NoError(t, err)
+	require.Nil(t, entry)
+
+	_, err = h.FindByMergeBase("target")
+	require.ErrorContains(t, err, "fetch more history")
+}
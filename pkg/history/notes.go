@@ -0,0 +1,284 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/storage"
+)
+
+// NotesRef is the default git notes ref holding go-covercheck history.
+const NotesRef = "refs/notes/covercheck"
+
+// notesAuthor signs the commits of the notes ref.
+var notesAuthor = object.Signature{
+	Name:  "go-covercheck",
+	Email: "go-covercheck@users.noreply.github.com",
+}
+
+// notesSaveAttempts is how many times a save is tried when the notes ref
+// moved while the entries were being changed.
+const notesSaveAttempts = 5
+
+// errNotesMoved is returned by a save attempt that lost a race to update the
+// notes ref.
+var errNotesMoved = errors.New("history notes were updated concurrently")
+
+// notes is a Storage keeping History in the git notes of a repository.
+type notes struct {
+	repoPath string
+	ref      plumbing.ReferenceName
+	// base and tip hold the entries and the notes commit they were loaded
+	// from, so a save can replay the changes made since onto notes that moved
+	// in the meantime. tip is the zero hash when the ref did not exist.
+	base []Entry
+	tip  plumbing.Hash
+}
+
+// NewNotesStorage returns a Storage keeping History in the git notes under ref
+// of the repository at repoPath. Each note annotates the commit of its entries
+// and holds them in the same json form as the history file. A missing notes
+// ref is an empty History.
+func NewNotesStorage(repoPath, ref string) Storage {
+	return &notes{repoPath: repoPath, ref: plumbing.ReferenceName(ref)}
+}
+
+// LoadNotes loads History from the git notes under ref of the repository at
+// repoPath, see NewNotesStorage.
+func LoadNotes(repoPath, ref string) (*History, error) {
+	return LoadStorage(NewNotesStorage(repoPath, ref))
+}
+
+func (n *notes) Load() ([]Entry, error) {
+	repo, err := git.PlainOpen(n.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", n.repoPath, err)
+	}
+	entries, tip, err := n.read(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	n.base, n.tip = slices.Clone(entries), tip
+	return entries, nil
+}
+
+// read returns the entries of the notes along with the commit of the notes
+// ref, which is the zero hash when the ref does not exist yet.
+func (n *notes) read(repo *git.Repository) ([]Entry, plumbing.Hash, error) {
+	r, err := repo.Reference(n.ref, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, plumbing.ZeroHash, nil
+	}
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	commit, err := repo.CommitObject(r.Hash())
+	if err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("failed to read %s: %w", n.ref, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	var entries []Entry
+	err = tree.Files().ForEach(func(f *object.File) error {
+		contents, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		note, err := decodeHistory([]byte(contents))
+		if err != nil {
+			return fmt.Errorf("invalid history note for commit %s: %w", strings.ReplaceAll(f.Name, "/", ""), err)
+		}
+		entries = append(entries, note...)
+		return nil
+	})
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	sortEntries(entries)
+	return entries, r.Hash(), nil
+}
+
+// MigrateToNotes copies the entries of the history file at path into the git
+// notes under ref of the repository at repoPath, replacing noted entries of the
+// same commit and label, and returns the number of entries migrated and
+// skipped. Entries without a commit hash, such as those saved outside a git
+// repository, cannot be noted and are skipped.
+func MigrateToNotes(path, repoPath, ref string) (int, int, error) {
+	file, err := Load(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	h, err := LoadNotes(repoPath, ref)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	migrated, skipped := 0, 0
+	migrating := map[string]bool{}
+	for _, entry := range file.Entries {
+		if !plumbing.IsHash(entry.Commit) {
+			skipped++
+			continue
+		}
+		migrating[entry.Commit] = true
+	}
+	// entries of one commit, such as those of several labels, replace the
+	// notes that existed before rather than each other.
+	h.Entries = slices.DeleteFunc(h.Entries, func(e Entry) bool {
+		return migrating[e.Commit]
+	})
+	for _, entry := range file.Entries {
+		if migrating[entry.Commit] {
+			h.Entries = append(h.Entries, entry)
+			migrated++
+		}
+	}
+
+	sortEntries(h.Entries)
+	return migrated, skipped, h.Save(0)
+}
+
+// Save replaces the notes with one note per commit of entries, limited to
+// limit when greater than 0, recorded as a new commit on the notes ref so
+// earlier history stays reachable. When the notes ref moved since the entries
+// were loaded, the notes are read again, the changes made to the entries since
+// are replayed onto them, and the save retried. It returns the entries as
+// noted.
+func (n *notes) Save(entries []Entry, key string, limit int) ([]Entry, error) {
+	repo, err := git.PlainOpen(n.repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", n.repoPath, err)
+	}
+
+	latest, tip := n.base, n.tip
+	for attempt := range notesSaveAttempts {
+		if attempt > 0 {
+			if latest, tip, err = n.read(repo); err != nil {
+				return nil, err
+			}
+		}
+
+		merged := limitEntries(replayChanges(n.base, entries, latest, key), limit)
+
+		commit, err := n.commit(repo, merged, tip)
+		if errors.Is(err, errNotesMoved) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		n.base, n.tip = slices.Clone(merged), commit
+		return merged, nil
+	}
+	return nil, fmt.Errorf("failed to save history to git notes %s after %d attempts: %w",
+		n.ref, notesSaveAttempts, errNotesMoved)
+}
+
+// commit records entries as one note per commit in a new commit on top of tip,
+// and compares and swaps the notes ref from tip to it.
+func (n *notes) commit(repo *git.Repository, entries []Entry, tip plumbing.Hash) (plumbing.Hash, error) {
+	byCommit := map[string][]Entry{}
+	for _, entry := range entries {
+		if !plumbing.IsHash(entry.Commit) {
+			return plumbing.ZeroHash, fmt.Errorf("cannot store history entry for commit %q as a git note",
+				entry.Commit)
+		}
+		byCommit[entry.Commit] = append(byCommit[entry.Commit], entry)
+	}
+
+	tree := &object.Tree{}
+	for commit, commitEntries := range byCommit {
+		b, err := encodeHistory(commitEntries)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		hash, err := writeBlob(repo, b)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: commit, Mode: filemode.Regular, Hash: hash})
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return tree.Entries[i].Name < tree.Entries[j].Name
+	})
+	treeHash, err := encodeObject(repo, tree)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	author := notesAuthor
+	author.When = time.Now()
+	commit := &object.Commit{
+		Author:    author,
+		Committer: author,
+		Message:   "Notes added by 'go-covercheck'\n",
+		TreeHash:  treeHash,
+	}
+	var old *plumbing.Reference
+	if !tip.IsZero() {
+		commit.ParentHashes = []plumbing.Hash{tip}
+		old = plumbing.NewHashReference(n.ref, tip)
+	} else if _, err := repo.Reference(n.ref, false); err == nil {
+		// the notes were created since they were found missing
+		return plumbing.ZeroHash, errNotesMoved
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return plumbing.ZeroHash, err
+	}
+	commitHash, err := encodeObject(repo, commit)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	err = repo.Storer.CheckAndSetReference(plumbing.NewHashReference(n.ref, commitHash), old)
+	if errors.Is(err, storage.ErrReferenceHasChanged) {
+		return plumbing.ZeroHash, errNotesMoved
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return commitHash, nil
+}
+
+// encoder is a git object that encodes itself, such as a tree or a commit.
+type encoder interface {
+	Encode(o plumbing.EncodedObject) error
+}
+
+func encodeObject(repo *git.Repository, e encoder) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	if err := e.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+func writeBlob(repo *git.Repository, b []byte) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(b)))
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(b); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
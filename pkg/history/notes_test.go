@@ -0,0 +1,211 @@
+package history //nolint:testpackage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// initNotesRepo creates a git repository with the given number of empty commits and
+// returns its path and the commit hashes, oldest first.
+func initNotesRepo(t *testing.T, commits int) (string, []string) {
+	t.Helper()
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+
+	var hashes []string
+	for i := range commits {
+		hash, err := w.Commit("commit", &git.CommitOptions{
+			AllowEmptyCommits: true,
+			Author: &object.Signature{
+				Name:  "Test",
+				Email: "test@example.com",
+				When:  time.Now().Add(time.Duration(i) * time.Second),
+			},
+		})
+		require.NoError(t, err)
+		hashes = append(hashes, hash.String())
+	}
+	return repoDir, hashes
+}
+
+func TestLoadNotes_MissingRef(t *testing.T) {
+	repoDir, _ := initNotesRepo(t, 1)
+
+	h, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	require.Empty(t, h.Entries)
+}
+
+func TestLoadNotes_NotARepository(t *testing.T) {
+	_, err := LoadNotes(t.TempDir(), NotesRef)
+	require.ErrorContains(t, err, "failed to open git repository")
+}
+
+func TestNotes_SaveAndLoad(t *testing.T) {
+	repoDir, commits := initNotesRepo(t, 3)
+
+	h, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	h.Entries = []Entry{
+		testEntry(commits[2], withLabel("third"), onDay(20)),
+		testEntry(commits[1], withLabel("second"), onDay(19)),
+		testEntry(commits[0], withLabel("first"), onDay(18)),
+	}
+	require.NoError(t, h.Save(2))
+
+	loaded, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 2)
+	require.Equal(t, "third", loaded.Entries[0].Label)
+	require.Equal(t, "second", loaded.Entries[1].Label)
+	require.Equal(t, commits[1], findByRef(t, loaded, commits[1][:7]).Commit)
+	require.Nil(t, findByRef(t, loaded, "first"))
+
+	require.True(t, deleteByRef(t, loaded, "third"))
+	require.NoError(t, loaded.Save(0))
+
+	loaded, err = LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 1)
+	require.Equal(t, "second", loaded.Entries[0].Label)
+
+	// each save is a commit on the notes ref, so earlier notes stay reachable.
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	ref, err := repo.Reference(plumbing.ReferenceName(NotesRef), true)
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	require.Len(t, commit.ParentHashes, 1)
+
+	// the note is stored under the annotated commit, as git notes expects.
+	tree, err := commit.Tree()
+	require.NoError(t, err)
+	_, err = tree.File(commits[1])
+	require.NoError(t, err)
+}
+
+func TestNotes_InterleavedSavesByCommit(t *testing.T) {
+	repoDir, commits := initNotesRepo(t, 1)
+
+	first, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	second, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	require.NoError(t, first.SetKey(KeyCommit))
+	require.NoError(t, second.SetKey(KeyCommit))
+
+	first.Entries = append(first.Entries, testEntry(commits[0], withLabel("unit"), onDay(18)))
+	require.NoError(t, first.Save(0))
+
+	// under the commit key the entry of the second runner replaces the one
+	// of the first rather than being kept beside it.
+	second.Entries = append(second.Entries, testEntry(commits[0], withLabel("integration"), onDay(19)))
+	require.NoError(t, second.Save(0))
+
+	loaded, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 1)
+	require.Equal(t, "integration", findByRef(t, loaded, commits[0][:7]).Label)
+}
+
+func TestNotes_SaveRetriesOnConcurrentUpdate(t *testing.T) {
+	repoDir, commits := initNotesRepo(t, 3)
+
+	// two runners load the notes before either saves.
+	first, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	second, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+
+	first.Entries = append(first.Entries, testEntry(commits[0], withLabel("first"), onDay(18)))
+	require.NoError(t, first.Save(0))
+
+	// the notes ref was created since the second runner loaded it, so its
+	// entry is replayed onto the notes of the first.
+	second.Entries = append(second.Entries, testEntry(commits[1], withLabel("second"), onDay(19)))
+	require.NoError(t, second.Save(0))
+	require.Len(t, second.Entries, 2)
+
+	// a later change on the first runner keeps the entry of the second.
+	require.True(t, deleteByRef(t, first, "first"))
+	first.Entries = append(first.Entries, testEntry(commits[2], withLabel("third"), onDay(20)))
+	require.NoError(t, first.Save(0))
+
+	loaded, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 2)
+	require.Equal(t, "third", loaded.Entries[0].Label)
+	require.Equal(t, "second", loaded.Entries[1].Label)
+
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	ref, err := repo.Reference(plumbing.ReferenceName(NotesRef), true)
+	require.NoError(t, err)
+	commitsOnRef := 0
+	iter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+	require.NoError(t, err)
+	require.NoError(t, iter.ForEach(func(*object.Commit) error {
+		commitsOnRef++
+		return nil
+	}))
+	require.Equal(t, 3, commitsOnRef)
+}
+
+func TestNotes_SaveRejectsNonCommitEntries(t *testing.T) {
+	repoDir, _ := initNotesRepo(t, 1)
+
+	h, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	h.Entries = []Entry{testEntry("unknown", onDay(18))}
+	require.ErrorContains(t, h.Save(0), `cannot store history entry for commit "unknown" as a git note`)
+}
+
+func TestMigrateToNotes(t *testing.T) {
+	repoDir, commits := initNotesRepo(t, 2)
+
+	existing, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	existing.Entries = []Entry{testEntry(commits[0], withLabel("stale"), onDay(10))}
+	require.NoError(t, existing.Save(0))
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	file := New(path)
+	file.Entries = []Entry{
+		testEntry(commits[1], withLabel("second"), onDay(19)),
+		testEntry(commits[0], withLabel("first"), onDay(18)),
+		testEntry("unknown", withLabel("outside"), onDay(17)),
+	}
+	require.NoError(t, file.Save(0))
+
+	migrated, skipped, err := MigrateToNotes(path, repoDir, NotesRef)
+	require.NoError(t, err)
+	require.Equal(t, 2, migrated)
+	require.Equal(t, 1, skipped)
+
+	h, err := LoadNotes(repoDir, NotesRef)
+	require.NoError(t, err)
+	require.Len(t, h.Entries, 2)
+	require.Equal(t, "second", h.Entries[0].Label)
+	require.Equal(t, "first", h.Entries[1].Label)
+
+	// the history file is left in place.
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+}
+
+func TestMigrateToNotes_MissingFile(t *testing.T) {
+	repoDir, _ := initNotesRepo(t, 1)
+	_, _, err := MigrateToNotes(filepath.Join(t.TempDir(), "missing.json"), repoDir, NotesRef)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,193 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/gitdiff"
+)
+
+// commitRangeSeparator separates the ends of a commit range such as
+// "v1.0.0..main".
+const commitRangeSeparator = ".."
+
+// Query selects the History entries matching all of its criteria. Criteria
+// left unset match every entry.
+type Query struct {
+	// Branches matches entries recorded on one of these branches.
+	Branches []string
+	// Label matches entries whose label matches this regular expression.
+	Label string
+	// Tagged matches entries of tagged commits.
+	Tagged bool
+	// Since matches entries recorded at or after this date (2025-07-01), time
+	// (RFC 3339), or age (30d, 12w, or 720h ago).
+	Since string
+	// Until matches entries recorded before the end of this date, or before
+	// this time or age.
+	Until string
+	// Commits matches entries of the commits in the git range from..to, being
+	// the ancestors of to that are not ancestors of from. Either end defaults
+	// to HEAD.
+	Commits string
+	// Build matches entries whose Build has these fields, see Build.Matches.
+	Build map[string]string
+}
+
+// IsSet reports whether any criterion is set.
+func (q Query) IsSet() bool {
+	return len(q.Branches) > 0 || q.Label != "" || q.Tagged || q.Since != "" || q.Until != "" ||
+		q.Commits != "" || len(q.Build) > 0
+}
+
+// Select keeps the entries of the History matching q as of now.
+func (h *History) Select(q Query, now time.Time) error {
+	if !q.IsSet() {
+		return nil
+	}
+
+	var label *regexp.Regexp
+	if q.Label != "" {
+		var err error
+		if label, err = regexp.Compile(q.Label); err != nil {
+			return fmt.Errorf("invalid label pattern %q: %w", q.Label, err)
+		}
+	}
+	since, err := parseTimeBound(q.Since, now, false)
+	if err != nil {
+		return fmt.Errorf("invalid since: %w", err)
+	}
+	until, err := parseTimeBound(q.Until, now, true)
+	if err != nil {
+		return fmt.Errorf("invalid until: %w", err)
+	}
+	var commits map[string]bool
+	if q.Commits != "" {
+		if commits, err = commitRange(defaultRepoPath, q.Commits); err != nil {
+			return err
+		}
+	}
+
+	h.Filter(func(entry Entry) bool {
+		return (len(q.Branches) == 0 || slices.Contains(q.Branches, entry.Branch)) &&
+			(label == nil || label.MatchString(entry.Label)) &&
+			(!q.Tagged || len(entry.Tags) > 0) &&
+			(since.IsZero() || !entry.Timestamp.Before(since)) &&
+			(until.IsZero() || entry.Timestamp.Before(until)) &&
+			(commits == nil || commits[entry.Commit]) &&
+			(len(q.Build) == 0 || entry.Build.Matches(q.Build))
+	})
+	return nil
+}
+
+// parseTimeBound parses a date, an RFC 3339 time, or an age before now, see
+// config.ParseAge. A date is the start of the day, or the end of it when end
+// is set. An empty value is the zero time.
+func parseTimeBound(value string, now time.Time, end bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	age, err := config.ParseAge(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date (2025-07-01), a time (RFC 3339), or an age (30d)", value)
+	}
+	return now.Add(-age), nil
+}
+
+// maxCommitRangeDepth is the number of generations of ancestors walked from
+// each end of a commit range, like maxFirstParentDepth for the merge-base walk.
+//
+// Defined as a package variable to allow testing with a smaller depth.
+var maxCommitRangeDepth = 1000
+
+// commitRange returns the commits of the git range from..to in the repository
+// at repoPath, being the ancestors of to, inclusive, that are not ancestors of
+// from. Up to maxCommitRangeDepth generations of ancestors of each end are
+// walked, stopping at parents missing from a shallow clone.
+func commitRange(repoPath, spec string) (map[string]bool, error) {
+	from, to, found := strings.Cut(spec, commitRangeSeparator)
+	if !found || strings.HasPrefix(to, ".") {
+		return nil, fmt.Errorf("invalid commit range %q, expected from..to", spec)
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", repoPath, err)
+	}
+	ancestors := func(ref string) (map[string]bool, error) {
+		if ref == "" {
+			ref = plumbing.HEAD.String()
+		}
+		hash, err := gitdiff.ResolveReference(repo, ref)
+		if err != nil {
+			return nil, err
+		}
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit of %q: %w", ref, err)
+		}
+		return ancestorsOf(repo, commit)
+	}
+
+	excluded, err := ancestors(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit range %q: %w", spec, err)
+	}
+	included, err := ancestors(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit range %q: %w", spec, err)
+	}
+	for commit := range excluded {
+		delete(included, commit)
+	}
+	return included, nil
+}
+
+// ancestorsOf returns commit and its ancestors within maxCommitRangeDepth
+// generations, leaving out those past a parent missing from a shallow clone.
+func ancestorsOf(repo *git.Repository, commit *object.Commit) (map[string]bool, error) {
+	commits := map[string]bool{}
+	generation := []*object.Commit{commit}
+	for depth := 0; depth <= maxCommitRangeDepth && len(generation) > 0; depth++ {
+		var parents []*object.Commit
+		for _, c := range generation {
+			if commits[c.Hash.String()] {
+				continue
+			}
+			commits[c.Hash.String()] = true
+			for _, hash := range c.ParentHashes {
+				if commits[hash.String()] {
+					continue
+				}
+				parent, err := repo.CommitObject(hash)
+				if errors.Is(err, plumbing.ErrObjectNotFound) {
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to get the parent of commit %s: %w", c.Hash, err)
+				}
+				parents = append(parents, parent)
+			}
+		}
+		generation = parents
+	}
+	return commits, nil
+}
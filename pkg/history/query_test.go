@@ -0,0 +1,129 @@
+package history //nolint:testpackage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_Select(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Commit: "c1", Branch: "main", Label: "unit", Tags: []string{"v1.0.0"}, Timestamp: now.Add(-time.Hour)},
+		{Commit: "c2", Branch: "main", Label: "integration", Timestamp: time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)},
+		{Commit: "c3", Branch: "feature", Label: "unit-race", Timestamp: time.Date(2025, 6, 30, 23, 0, 0, 0, time.UTC)},
+		{Commit: "c4", Branch: "main", Build: &Build{Runner: "linux"}, Timestamp: time.Date(2025, 9, 30, 0, 0, 0, 0,
+			time.UTC)},
+	}
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "unset", query: Query{}, want: []string{"c1", "c2", "c3", "c4"}},
+		{name: "branches", query: Query{Branches: []string{"feature"}}, want: []string{"c3"}},
+		{name: "label", query: Query{Label: "^unit"}, want: []string{"c1", "c3"}},
+		{name: "tagged", query: Query{Tagged: true}, want: []string{"c1"}},
+		{name: "q3 on main", query: Query{Branches: []string{"main"}, Since: "2025-07-01", Until: "2025-09-30"},
+			want: []string{"c2", "c4"}},
+		{name: "since age", query: Query{Since: "1d"}, want: []string{"c1"}},
+		{name: "until time", query: Query{Until: "2025-07-01T00:00:00Z"}, want: []string{"c3"}},
+		{name: "build", query: Query{Build: map[string]string{"runner": "linux"}}, want: []string{"c4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &History{Entries: append([]Entry(nil), entries...)}
+			require.NoError(t, h.Select(tt.query, now))
+			var commits []string
+			for _, entry := range h.Entries {
+				commits = append(commits, entry.Commit)
+			}
+			require.Equal(t, tt.want, commits)
+		})
+	}
+}
+
+func TestHistory_Select_Invalid(t *testing.T) {
+	h := &History{}
+	require.ErrorContains(t, h.Select(Query{Label: "("}, time.Now()), `invalid label pattern "("`)
+	require.ErrorContains(t, h.Select(Query{Since: "yesterday"}, time.Now()),
+		`invalid since: "yesterday" is not a date (2025-07-01), a time (RFC 3339), or an age (30d)`)
+	require.ErrorContains(t, h.Select(Query{Until: "07/01/2025"}, time.Now()), "invalid until")
+}
+
+func TestHistory_Select_Commits(t *testing.T) {
+	repoDir, commits := initForkedRepo(t)
+	defaultRepoPath = repoDir
+	defer func() {
+		defaultRepoPath = "."
+	}()
+
+	var entries []Entry
+	for _, commit := range commits {
+		entries = append(entries, Entry{Commit: commit})
+	}
+	for spec, want := range map[string][]string{
+		commits[1] + "..feature":  {commits[2], commits[3]},
+		commits[1] + "..":         {commits[2], commits[3]},
+		"feature.." + commits[4]:  {commits[4]},
+		commits[2] + "~1..HEAD~1": {commits[2]},
+	} {
+		h := &History{Entries: append([]Entry(nil), entries...)}
+		require.NoError(t, h.Select(Query{Commits: spec}, time.Now()), spec)
+		var got []string
+		for _, entry := range h.Entries {
+			got = append(got, entry.Commit)
+		}
+		require.Equal(t, want, got, spec)
+	}
+
+	h := &History{Entries: entries}
+	require.ErrorContains(t, h.Select(Query{Commits: "feature"}, time.Now()),
+		`invalid commit range "feature", expected from..to`)
+	require.ErrorContains(t, h.Select(Query{Commits: commits[1] + "...feature"}, time.Now()), "expected from..to")
+	require.ErrorContains(t, h.Select(Query{Commits: "no-such-ref..feature"}, time.Now()),
+		`invalid commit range "no-such-ref..feature"`)
+}
+
+func TestHistory_Select_CommitsShallowClone(t *testing.T) {
+	repoDir, commits := initForkedRepo(t)
+	cloneDir := t.TempDir()
+	_, err := git.PlainClone(cloneDir, &git.CloneOptions{URL: "file://" + repoDir, Depth: 2})
+	require.NoError(t, err)
+	defaultRepoPath = cloneDir
+	defer func() {
+		defaultRepoPath = "."
+		maxCommitRangeDepth = 1000
+	}()
+
+	var entries []Entry
+	for _, commit := range commits {
+		entries = append(entries, Entry{Commit: commit})
+	}
+	selected := func(spec string) []string {
+		h := &History{Entries: append([]Entry(nil), entries...)}
+		require.NoError(t, h.Select(Query{Commits: spec}, time.Now()), spec)
+		var got []string
+		for _, entry := range h.Entries {
+			got = append(got, entry.Commit)
+		}
+		return got
+	}
+
+	// the walk stops at the first commit, which is missing from the clone
+	require.Equal(t, []string{commits[2], commits[3]}, selected(commits[4]+"..HEAD"))
+	require.Equal(t, []string{commits[4]}, selected("HEAD.."+commits[4]))
+
+	// and at parents whose objects are missing, as in clones that do not
+	// record where they were cut
+	require.NoError(t, os.Remove(filepath.Join(cloneDir, ".git", "shallow")))
+	require.Equal(t, []string{commits[2], commits[3]}, selected(commits[4]+"..HEAD"))
+
+	// and after maxCommitRangeDepth generations
+	maxCommitRangeDepth = 0
+	require.Equal(t, []string{commits[3]}, selected(commits[4]+"..HEAD"))
+}
@@ -0,0 +1,173 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/mach6/go-covercheck/pkg/gitdiff"
+)
+
+// ErrAmbiguousRef is returned when a commit prefix matches entries of more
+// than one commit, or a ref of a commit matches its entries of several labels.
+var ErrAmbiguousRef = errors.New("ambiguous ref")
+
+// minCommitPrefix is the length of the shortest commit prefix that matches
+// entries.
+const minCommitPrefix = 7
+
+// labeledRef is a ref, optionally qualified with the label of the entries it
+// selects.
+type labeledRef struct {
+	ref       string
+	label     string
+	qualified bool
+}
+
+// selects reports whether entry has the label r is qualified with, if any.
+func (r labeledRef) selects(entry Entry) bool {
+	return !r.qualified || entry.Label == r.label
+}
+
+// matchesRef reports whether ref is the commit, the branch, a tag, or the
+// label of the entry, or one of the former qualified with the label of the
+// entry as "ref@label".
+func (e Entry) matchesRef(ref string) bool {
+	if e.matchesUnqualifiedRef(ref) || e.Label == ref {
+		return true
+	}
+	if e.Label == "" {
+		return false
+	}
+	unqualified, found := strings.CutSuffix(ref, refLabelSeparator+e.Label)
+	return found && unqualified != "" && e.matchesUnqualifiedRef(unqualified)
+}
+
+func (e Entry) matchesUnqualifiedRef(ref string) bool {
+	if e.Commit == ref || e.Branch == ref {
+		return true
+	}
+	for _, t := range e.Tags {
+		if t == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// labeledRefs returns ref unqualified, followed by ref split as "ref@label"
+// for each label of the entries that ref is qualified with.
+func (h *History) labeledRefs(ref string) []labeledRef {
+	refs := []labeledRef{{ref: ref}}
+	seen := map[string]bool{}
+	for _, entry := range h.Entries {
+		if entry.Label == "" || seen[entry.Label] {
+			continue
+		}
+		seen[entry.Label] = true
+		if unqualified, found := strings.CutSuffix(ref, refLabelSeparator+entry.Label); found && unqualified != "" {
+			refs = append(refs, labeledRef{ref: unqualified, label: entry.Label, qualified: true})
+		}
+	}
+	return refs
+}
+
+// indexOfRef returns the index of the first entry that ref resolves to, or -1
+// when there is none. Entries recorded with ref as their commit, branch, tag,
+// or label are matched first. Otherwise ref is matched as a prefix of at least
+// seven characters of a commit, and finally as a git revision, such as
+// HEAD~1, origin/main, or an annotated tag, resolved in the repository. A ref
+// that selects a commit rather than a branch or label fails with
+// ErrAmbiguousRef when the commit has entries of several labels, unless it is
+// qualified with one as "ref@label".
+func (h *History) indexOfRef(ref string) (int, error) {
+	for i, entry := range h.Entries {
+		if entry.matchesRef(ref) {
+			if entry.Branch == ref || entry.Label == ref || !entry.matchesUnqualifiedRef(ref) {
+				return i, nil
+			}
+			return i, h.checkCommitLabels(ref, i)
+		}
+	}
+
+	refs := h.labeledRefs(ref)
+	for _, r := range refs {
+		i, err := h.indexOfCommitPrefix(r)
+		if err == nil && i >= 0 && !r.qualified {
+			err = h.checkCommitLabels(ref, i)
+		}
+		if err != nil || i >= 0 {
+			return i, err
+		}
+	}
+
+	repo, err := git.PlainOpen(defaultRepoPath)
+	if err != nil {
+		return -1, nil
+	}
+	for _, r := range refs {
+		hash, err := gitdiff.ResolveReference(repo, r.ref)
+		if err != nil {
+			continue
+		}
+		for i, entry := range h.Entries {
+			if !r.selects(entry) || entry.Commit != hash.String() {
+				continue
+			}
+			if r.qualified {
+				return i, nil
+			}
+			return i, h.checkCommitLabels(ref, i)
+		}
+	}
+	return -1, nil
+}
+
+// checkCommitLabels fails with ErrAmbiguousRef when the commit of the entry at
+// i has entries of several labels, so that ref, which selects the commit, must
+// be qualified with one of them.
+func (h *History) checkCommitLabels(ref string, i int) error {
+	commit := h.Entries[i].Commit
+	var labels []string
+	for _, entry := range h.Entries {
+		if entry.Commit == commit && !slices.Contains(labels, entry.Label) {
+			labels = append(labels, entry.Label)
+		}
+	}
+	if len(labels) < 2 { //nolint:mnd // a single label is not ambiguous
+		return nil
+	}
+	slices.Sort(labels)
+	quoted := make([]string, 0, len(labels))
+	for _, label := range labels {
+		quoted = append(quoted, strconv.Quote(label))
+	}
+	return fmt.Errorf("%w %q, it matches the entries of commit %s labeled %s, use %s%slabel",
+		ErrAmbiguousRef, ref, QualifiedRef(commit, ""), strings.Join(quoted, ", "), ref, refLabelSeparator)
+}
+
+// indexOfCommitPrefix returns the index of the first entry whose commit starts
+// with the ref of r, or -1 when there is none. It fails with ErrAmbiguousRef
+// when entries of several commits start with it.
+func (h *History) indexOfCommitPrefix(r labeledRef) (int, error) {
+	if len(r.ref) < minCommitPrefix {
+		return -1, nil
+	}
+	index := -1
+	for i, entry := range h.Entries {
+		if !r.selects(entry) || !strings.HasPrefix(entry.Commit, r.ref) {
+			continue
+		}
+		if index < 0 {
+			index = i
+			continue
+		}
+		if commit := h.Entries[index].Commit; entry.Commit != commit {
+			return -1, fmt.Errorf("%w %q, it matches commits %s and %s", ErrAmbiguousRef, r.ref, commit, entry.Commit)
+		}
+	}
+	return index, nil
+}
@@ -0,0 +1,113 @@
+package history //nolint:testpackage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// findByRef returns the entry of h that ref resolves to, failing the test on
+// error.
+func findByRef(t *testing.T, h *History, ref string) *Entry {
+	t.Helper()
+	entry, err := h.FindByRef(ref)
+	require.NoError(t, err)
+	return entry
+}
+
+// deleteByRef deletes the entry of h that ref resolves to, failing the test on
+// error.
+func deleteByRef(t *testing.T, h *History, ref string) bool {
+	t.Helper()
+	deleted, err := h.DeleteByRef(ref)
+	require.NoError(t, err)
+	return deleted
+}
+
+func TestFindByRef_CommitPrefix(t *testing.T) {
+	h := &History{Entries: []Entry{
+		{Commit: "abc1234aaa1111", Label: "unit"},
+		{Commit: "abc1234aaa1111", Label: "integration"},
+		{Commit: "abc1234bbb2222", Label: "unit"},
+		{Commit: "unknown", Label: "e2e"},
+	}}
+
+	// entries of one commit with different labels need the ref qualified
+	for _, ref := range []string{"abc1234aaa", "abc1234aaa1111"} {
+		_, err := h.FindByRef(ref)
+		require.ErrorIs(t, err, ErrAmbiguousRef, ref)
+		require.ErrorContains(t, err, `labeled "integration", "unit", use `+ref+"@label", ref)
+	}
+
+	require.Equal(t, "abc1234bbb2222", findByRef(t, h, "abc1234bbb").Commit)
+	require.Equal(t, "integration", findByRef(t, h, "abc1234aaa@integration").Label)
+	require.Equal(t, "abc1234aaa1111", findByRef(t, h, "abc1234a@unit").Commit)
+	require.Nil(t, findByRef(t, h, "abc123"))
+	require.Nil(t, findByRef(t, h, "unknown1234"))
+	require.Equal(t, "unknown", findByRef(t, h, "unknown").Commit)
+
+	_, err := h.FindByRef("abc1234")
+	require.ErrorIs(t, err, ErrAmbiguousRef)
+	require.ErrorContains(t, err, "matches commits abc1234aaa1111 and abc1234bbb2222")
+
+	_, err = h.FindByRef("abc1234@unit")
+	require.ErrorIs(t, err, ErrAmbiguousRef)
+
+	deleted, err := h.DeleteByRef("abc1234")
+	require.ErrorIs(t, err, ErrAmbiguousRef)
+	require.False(t, deleted)
+	require.Len(t, h.Entries, 4)
+}
+
+func TestFindByRef_GitRevision(t *testing.T) {
+	repoDir, commits := initNotesRepo(t, 3)
+	defaultRepoPath = repoDir
+	defer func() {
+		defaultRepoPath = "."
+	}()
+
+	repo, err := git.PlainOpen(repoDir)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.0.0", plumbing.NewHash(commits[0]), &git.CreateTagOptions{
+		Message: "release",
+		Tagger:  &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewRemoteReferenceName("origin", "main"), plumbing.NewHash(commits[1]))))
+
+	h := &History{Entries: []Entry{
+		{Commit: commits[2], Branch: "feature", Label: "unit"},
+		{Commit: commits[1], Branch: "feature", Label: "integration"},
+		{Commit: commits[1], Branch: "feature", Label: "unit"},
+		{Commit: commits[0], Branch: "feature"},
+	}}
+
+	for ref, want := range map[string]Entry{
+		"HEAD":               h.Entries[0],
+		"HEAD~1@integration": h.Entries[1],
+		"HEAD~1@unit":        h.Entries[2],
+		"origin/main@unit":   h.Entries[2],
+		"v1.0.0":             h.Entries[3],
+		commits[0][:5]:       h.Entries[3],
+	} {
+		entry := findByRef(t, h, ref)
+		require.NotNil(t, entry, ref)
+		require.Equal(t, want.Commit, entry.Commit, ref)
+		require.Equal(t, want.Label, entry.Label, ref)
+	}
+	require.Nil(t, findByRef(t, h, "HEAD~1@e2e"))
+	require.Nil(t, findByRef(t, h, "no-such-ref"))
+	for _, ref := range []string{"HEAD~1", "origin/main"} {
+		_, err := h.FindByRef(ref)
+		require.ErrorIs(t, err, ErrAmbiguousRef, ref)
+	}
+
+	require.True(t, deleteByRef(t, h, "HEAD~1@unit"))
+	require.Len(t, h.Entries, 3)
+	require.Equal(t, "integration", findByRef(t, h, "HEAD~1").Label)
+}
@@ -0,0 +1,77 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mach6/go-covercheck/pkg/config"
+)
+
+// thinKey identifies the period of a branch and label that thinning keeps a
+// single entry for.
+type thinKey struct {
+	branch string
+	label  string
+	period string
+}
+
+// Prune removes the entries that retention does not keep as of now and returns
+// them, newest first. Entries of tagged commits and labeled entries are always
+// kept when retention says so; the other entries are dropped when older than
+// the max age, when an entry of the same branch, label, and day or week was
+// kept already and they are old enough to be thinned, or when their branch has
+// the per-branch number of newer entries kept already.
+func (h *History) Prune(retention config.Retention, now time.Time) ([]Entry, error) {
+	maxAge, err := retention.MaxAgeDuration()
+	if err != nil {
+		return nil, err
+	}
+	thinAfter, err := retention.ThinAfterDuration()
+	if err != nil {
+		return nil, err
+	}
+
+	sortEntries(h.Entries)
+	var kept, removed []Entry
+	perBranch := map[string]int{}
+	thinned := map[thinKey]bool{}
+	for _, entry := range h.Entries {
+		if (retention.KeepTagged && len(entry.Tags) > 0) || (retention.KeepLabeled && entry.Label != "") {
+			kept = append(kept, entry)
+			continue
+		}
+
+		age := now.Sub(entry.Timestamp)
+		key := thinKey{branch: entry.Branch, label: entry.Label, period: thinPeriod(entry.Timestamp, retention.Thin)}
+		thin := key.period != "" && age > thinAfter
+		switch {
+		case maxAge > 0 && age > maxAge,
+			thin && thinned[key],
+			retention.PerBranch > 0 && perBranch[entry.Branch] >= retention.PerBranch:
+			removed = append(removed, entry)
+		default:
+			kept = append(kept, entry)
+			perBranch[entry.Branch]++
+			if thin {
+				thinned[key] = true
+			}
+		}
+	}
+
+	h.Entries = kept
+	return removed, nil
+}
+
+// thinPeriod returns the day or ISO week of t when thin is config.ThinDay or
+// config.ThinWeek, and "" otherwise.
+func thinPeriod(t time.Time, thin string) string {
+	switch thin {
+	case config.ThinDay:
+		return t.UTC().Format(time.DateOnly)
+	case config.ThinWeek:
+		year, week := t.UTC().ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,85 @@
+package history //nolint:testpackage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func commitsOf(entries []Entry) []string {
+	commits := make([]string, 0, len(entries))
+	for _, e := range entries {
+		commits = append(commits, e.Commit)
+	}
+	return commits
+}
+
+func TestHistory_Prune(t *testing.T) {
+	now := time.Date(2025, 7, 30, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tagged := testEntry("tagged", at(now.Add(-100*day)))
+	tagged.Tags = []string{"v1.0.0"}
+	labeled := testEntry("labeled", onBranch("feature"), withLabel("nightly"), at(now.Add(-3*day)))
+	entries := []Entry{
+		testEntry("f1", onBranch("feature"), at(now.Add(-time.Hour))),
+		testEntry("f2", onBranch("feature"), at(now.Add(-2*time.Hour))),
+		testEntry("f3", onBranch("feature"), at(now.Add(-2*day))),
+		labeled,
+		testEntry("m1", at(now.Add(-20*day))),
+		testEntry("m2", at(now.Add(-(20*day + time.Hour)))),
+		testEntry("m3", at(now.Add(-40*day))),
+		testEntry("m4", at(now.Add(-41*day))),
+		testEntry("m5", at(now.Add(-60*day))),
+		tagged,
+	}
+
+	tests := map[string]struct {
+		retention config.Retention
+		kept      []string
+		removed   []string
+	}{
+		"per branch": {
+			retention: config.Retention{PerBranch: 2, KeepTagged: true},
+			kept:      []string{"f1", "f2", "m1", "m2", "tagged"},
+			removed:   []string{"f3", "labeled", "m3", "m4", "m5"},
+		},
+		"keep labeled": {
+			retention: config.Retention{PerBranch: 2, KeepLabeled: true},
+			kept:      []string{"f1", "f2", "labeled", "m1", "m2"},
+			removed:   []string{"f3", "m3", "m4", "m5", "tagged"},
+		},
+		"max age": {
+			retention: config.Retention{MaxAge: "30d", KeepTagged: true},
+			kept:      []string{"f1", "f2", "f3", "labeled", "m1", "m2", "tagged"},
+			removed:   []string{"m3", "m4", "m5"},
+		},
+		"thin by day after": {
+			retention: config.Retention{Thin: config.ThinDay, ThinAfter: "1d"},
+			kept:      []string{"f1", "f2", "f3", "labeled", "m1", "m3", "m4", "m5", "tagged"},
+			removed:   []string{"m2"},
+		},
+		"thin by week": {
+			retention: config.Retention{Thin: config.ThinWeek},
+			kept:      []string{"f1", "labeled", "m1", "m3", "m5", "tagged"},
+			removed:   []string{"f2", "f3", "m2", "m4"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := &History{Entries: append([]Entry(nil), entries...)}
+			removed, err := h.Prune(tc.retention, now)
+			require.NoError(t, err)
+			require.Equal(t, tc.kept, commitsOf(h.Entries))
+			require.Equal(t, tc.removed, commitsOf(removed))
+		})
+	}
+}
+
+func TestHistory_Prune_InvalidAge(t *testing.T) {
+	h := &History{}
+	_, err := h.Prune(config.Retention{MaxAge: "ages"}, time.Now())
+	require.ErrorContains(t, err, `invalid age "ages"`)
+}
@@ -0,0 +1,346 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// History URI schemes. A history URI without a scheme is a file path.
+const (
+	SchemeFile      = "file"
+	SchemeDir       = "dir"
+	SchemeGitNotes  = "git-notes"
+	SchemeGitBranch = "git-branch"
+)
+
+// defaultBranchFile is the history file of a git-branch URI without a file
+// parameter.
+const defaultBranchFile = ".go-covercheck.history.json"
+
+// Storage stores the entries of a History. Load may return an error wrapping
+// os.ErrNotExist when nothing was stored yet.
+type Storage interface {
+	// Load returns the stored entries, newest first.
+	Load() ([]Entry, error)
+	// Save replaces the stored entries with entries, keeping at most limit of
+	// the newest when limit is greater than 0, and returns them as stored. The
+	// key identifies an entry, see History.SetKey, when replaying the changes
+	// made to entries onto those another process saved in the meantime.
+	Save(entries []Entry, key string, limit int) ([]Entry, error)
+}
+
+// OpenFunc opens the Storage at location, the part of a history URI after
+// "scheme://".
+type OpenFunc func(location string) (Storage, error)
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]OpenFunc{
+		SchemeFile: func(location string) (Storage, error) {
+			return NewFileStorage(location), nil
+		},
+		SchemeDir: func(location string) (Storage, error) {
+			return NewDirStorage(location), nil
+		},
+		SchemeGitNotes: func(location string) (Storage, error) {
+			if location == "" {
+				location = NotesRef
+			}
+			return NewNotesStorage(defaultRepoPath, location), nil
+		},
+		SchemeGitBranch: openBranchStorage,
+	}
+)
+
+// RegisterScheme makes a Storage available to OpenStorage under scheme,
+// replacing any registered before.
+func RegisterScheme(scheme string, open OpenFunc) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	schemes[scheme] = open
+}
+
+// ParseURI splits a history URI into its scheme and location. A URI without a
+// scheme is a file path.
+func ParseURI(uri string) (string, string) {
+	scheme, location, found := strings.Cut(uri, "://")
+	if !found {
+		return SchemeFile, uri
+	}
+	return scheme, location
+}
+
+// OpenStorage opens the Storage of a history URI:
+//
+//	path or file://path                   a json file holding every entry
+//	dir://path                            a directory holding a json file per entry
+//	git-notes://[ref]                     git notes, under refs/notes/covercheck by default
+//	git-branch://branch[?remote=&file=]   a json file committed at the root of an orphan branch
+func OpenStorage(uri string) (Storage, error) {
+	scheme, location := ParseURI(uri)
+
+	schemesMu.RLock()
+	open, ok := schemes[scheme]
+	schemesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported history storage scheme %q", scheme)
+	}
+	return open(location)
+}
+
+func openBranchStorage(location string) (Storage, error) {
+	name, rawQuery, _ := strings.Cut(location, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s history URI: %w", SchemeGitBranch, err)
+	}
+	if name == "" {
+		name = Branch
+	}
+	file := query.Get("file")
+	if file == "" {
+		file = defaultBranchFile
+	}
+	// the file is committed at the root of the branch tree, whose entries
+	// cannot name a path.
+	if strings.ContainsAny(file, `/\`) {
+		return nil, fmt.Errorf("invalid %s history URI: file %q must not contain a path separator",
+			SchemeGitBranch, file)
+	}
+	return NewBranchStorage(defaultRepoPath, query.Get("remote"), name, file), nil
+}
+
+// limitEntries keeps at most limit of the newest entries when limit is greater
+// than 0.
+func limitEntries(entries []Entry, limit int) []Entry {
+	if limit > 0 && limit < len(entries) {
+		return entries[:limit]
+	}
+	return entries
+}
+
+// fileStorage stores every entry in a single json file. Saves hold an
+// advisory lock on a ".lock" file next to it, so concurrent saves of separate
+// processes merge rather than drop each other's entries.
+type fileStorage struct {
+	path string
+	// base holds the entries as last loaded or saved, so a save can replay the
+	// changes made since onto entries saved by another process in the meantime.
+	base []Entry
+}
+
+// NewFileStorage returns a Storage keeping every entry in the json file at
+// path.
+func NewFileStorage(path string) Storage {
+	return &fileStorage{path: path}
+}
+
+func (s *fileStorage) Load() ([]Entry, error) {
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	s.base = slices.Clone(entries)
+	return entries, nil
+}
+
+func (s *fileStorage) read() ([]Entry, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeHistory(b)
+}
+
+// Save reads the file again under the lock, replays the changes made to the
+// entries since they were loaded onto it, and atomically replaces the file.
+func (s *fileStorage) Save(entries []Entry, key string, limit int) ([]Entry, error) {
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock history file %s: %w", s.path, err)
+	}
+	defer unlock()
+
+	latest, err := s.read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	merged := limitEntries(replayChanges(s.base, entries, latest, key), limit)
+
+	b, err := encodeHistory(merged)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(s.path, b); err != nil {
+		return nil, err
+	}
+	s.base = slices.Clone(merged)
+	return merged, nil
+}
+
+// dirStorage stores each entry in its own json file of a directory, so entries
+// saved from different branches do not conflict. Saves hold an advisory lock
+// on a ".lock" file in the directory, like fileStorage.
+type dirStorage struct {
+	path string
+	base []Entry
+}
+
+// NewDirStorage returns a Storage keeping each entry in a json file of the
+// directory at path, named after the commit of the entry.
+func NewDirStorage(path string) Storage {
+	return &dirStorage{path: path}
+}
+
+func (s *dirStorage) Load() ([]Entry, error) {
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	s.base = slices.Clone(entries)
+	return entries, nil
+}
+
+func (s *dirStorage) read() ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(s.path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		if _, err := os.Stat(s.path); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]Entry, 0, len(files))
+	for _, f := range files {
+		b, err := os.ReadFile(f) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+		entry, err := decodeEntry(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid history entry %s: %w", f, err)
+		}
+		entries = append(entries, entry)
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func (s *dirStorage) Save(entries []Entry, key string, limit int) ([]Entry, error) {
+	if err := os.MkdirAll(s.path, 0700); err != nil { //nolint:mnd
+		return nil, err
+	}
+	unlock, err := lockFile(filepath.Join(s.path, ".lock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock history directory %s: %w", s.path, err)
+	}
+	defer unlock()
+
+	latest, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	merged := limitEntries(replayChanges(s.base, entries, latest, key), limit)
+
+	keep := make(map[string]bool, len(merged))
+	for _, entry := range merged {
+		name := s.fileName(entry)
+		b, err := encodeEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(filepath.Join(s.path, name), b); err != nil {
+			return nil, err
+		}
+		keep[name] = true
+	}
+
+	files, err := filepath.Glob(filepath.Join(s.path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if !keep[filepath.Base(f)] {
+			if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+	s.base = slices.Clone(merged)
+	return merged, nil
+}
+
+// singleEntryPerID marks dirStorage as a singleEntryStorage, its file names
+// being those of the commit and label of each entry.
+func (s *dirStorage) singleEntryPerID() {}
+
+// fileName is the name of the json file of entry, being its commit followed
+// by "@label" when it has a label.
+func (s *dirStorage) fileName(entry Entry) string {
+	name := entry.Commit
+	if entry.Label != "" {
+		name += refLabelSeparator + entry.Label
+	}
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(name) + ".json"
+}
+
+// entryID identifies an entry across saves by its commit, and by its label
+// under KeyCommitLabel, so the entries of several labels recorded for one
+// commit are kept apart only when the History is keyed by both.
+type entryID struct {
+	commit string
+	label  string
+}
+
+func idOf(e Entry, key string) entryID {
+	if key == KeyCommitLabel {
+		return entryID{commit: e.Commit, label: e.Label}
+	}
+	return entryID{commit: e.Commit}
+}
+
+// replayChanges applies the changes between base and current, being entries
+// added, replaced, or removed by their identity under key, onto latest and
+// returns the result sorted newest first. The entries of one identity change
+// together, so under KeyCommit an entry of another label saved concurrently
+// for the same commit is replaced.
+func replayChanges(base, current, latest []Entry, key string) []Entry {
+	baseByID := map[entryID][]Entry{}
+	for _, e := range base {
+		baseByID[idOf(e, key)] = append(baseByID[idOf(e, key)], e)
+	}
+	currentByID := map[entryID][]Entry{}
+	for _, e := range current {
+		currentByID[idOf(e, key)] = append(currentByID[idOf(e, key)], e)
+	}
+	changed := map[entryID]bool{}
+	for id, entries := range currentByID {
+		if old, ok := baseByID[id]; !ok || !reflect.DeepEqual(old, entries) {
+			changed[id] = true
+		}
+	}
+
+	merged := slices.DeleteFunc(slices.Clone(latest), func(e Entry) bool {
+		id := idOf(e, key)
+		_, inBase := baseByID[id]
+		_, inCurrent := currentByID[id]
+		return (inBase && !inCurrent) || changed[id]
+	})
+	for _, e := range current {
+		if changed[idOf(e, key)] {
+			merged = append(merged, e)
+		}
+	}
+	sortEntries(merged)
+	return merged
+}
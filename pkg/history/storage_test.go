@@ -0,0 +1,222 @@
+package history //nolint:testpackage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		uri      string
+		scheme   string
+		location string
+	}{
+		{".go-covercheck.history.json", SchemeFile, ".go-covercheck.history.json"},
+		{"file://history.json", SchemeFile, "history.json"},
+		{"dir:///tmp/history", SchemeDir, "/tmp/history"},
+		{"git-notes://", SchemeGitNotes, ""},
+		{"git-branch://history?remote=origin", SchemeGitBranch, "history?remote=origin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			scheme, location := ParseURI(tt.uri)
+			require.Equal(t, tt.scheme, scheme)
+			require.Equal(t, tt.location, location)
+		})
+	}
+}
+
+func TestOpenStorage(t *testing.T) {
+	s, err := OpenStorage("history.json")
+	require.NoError(t, err)
+	require.Equal(t, &fileStorage{path: "history.json"}, s)
+
+	s, err = OpenStorage("dir://history")
+	require.NoError(t, err)
+	require.Equal(t, &dirStorage{path: "history"}, s)
+
+	s, err = OpenStorage("git-notes://")
+	require.NoError(t, err)
+	require.Equal(t, &notes{repoPath: ".", ref: NotesRef}, s)
+
+	s, err = OpenStorage("git-notes://refs/notes/coverage")
+	require.NoError(t, err)
+	require.Equal(t, &notes{repoPath: ".", ref: "refs/notes/coverage"}, s)
+
+	s, err = OpenStorage("git-branch://")
+	require.NoError(t, err)
+	require.Equal(t, &branch{repoPath: ".", name: Branch, file: defaultBranchFile}, s)
+
+	s, err = OpenStorage("git-branch://ci/history?remote=origin&file=coverage.json")
+	require.NoError(t, err)
+	require.Equal(t, &branch{repoPath: ".", remote: "origin", name: "ci/history", file: "coverage.json"}, s)
+
+	// the file is committed at the root of the branch, so it cannot be nested.
+	_, err = OpenStorage("git-branch://ci/history?file=dir/history.json")
+	require.ErrorContains(t, err, `file "dir/history.json" must not contain a path separator`)
+
+	_, err = OpenStorage("s3://bucket/history.json")
+	require.ErrorContains(t, err, `unsupported history storage scheme "s3"`)
+}
+
+func TestRegisterScheme(t *testing.T) {
+	t.Cleanup(func() {
+		schemesMu.Lock()
+		delete(schemes, "test")
+		schemesMu.Unlock()
+	})
+
+	var opened string
+	RegisterScheme("test", func(location string) (Storage, error) {
+		opened = location
+		return NewFileStorage(filepath.Join(t.TempDir(), "history.json")), nil
+	})
+
+	s, err := OpenStorage("test://example.com/history")
+	require.NoError(t, err)
+	require.Equal(t, "example.com/history", opened)
+
+	h := NewWithStorage(s)
+	h.Entries = []Entry{testEntry("abc", onDay(1))}
+	require.NoError(t, h.Save(0))
+
+	loaded, err := LoadStorage(s)
+	require.NoError(t, err)
+	require.Equal(t, h.Entries, loaded.Entries)
+}
+
+func TestDirStorage_SaveAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+
+	_, err := Open("dir://" + dir)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	h := NewWithStorage(NewDirStorage(dir))
+	h.Entries = []Entry{
+		testEntry("ccc", onDay(3)),
+		testEntry("bbb", onDay(2)),
+		testEntry("aaa", onDay(1)),
+	}
+	require.NoError(t, h.Save(2))
+	require.Len(t, h.Entries, 2)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{filepath.Join(dir, "bbb.json"), filepath.Join(dir, "ccc.json")}, files)
+
+	loaded, err := Open("dir://" + dir)
+	require.NoError(t, err)
+	require.Equal(t, h.Entries, loaded.Entries)
+
+	// entries removed from the history are removed from the directory.
+	require.True(t, deleteByRef(t, loaded, "ccc"))
+	require.NoError(t, loaded.Save(0))
+	require.NoFileExists(t, filepath.Join(dir, "ccc.json"))
+
+	loaded, err = Open("dir://" + dir)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, 1)
+	require.Equal(t, "bbb", loaded.Entries[0].Commit)
+}
+
+func TestDirStorage_InvalidEntry(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0600))
+
+	_, err := LoadStorage(NewDirStorage(dir))
+	require.ErrorContains(t, err, "invalid history entry")
+}
+
+func TestFileStorage_ConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	require.NoError(t, NewWithStorage(NewFileStorage(path)).Save(0))
+
+	const saves = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, saves)
+	for i := range saves {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h, err := Load(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			h.Entries = append(h.Entries, testEntry(fmt.Sprintf("commit%d", i), onDay(i+1)))
+			errs <- h.Save(0)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, saves)
+	tmp, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	require.NoError(t, err)
+	require.Empty(t, tmp)
+}
+
+func TestFileStorage_SaveKeepsRemovals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h := New(path)
+	h.Entries = []Entry{testEntry("bbb", onDay(2)), testEntry("aaa", onDay(1))}
+	require.NoError(t, h.Save(0))
+
+	first, err := Load(path)
+	require.NoError(t, err)
+	second, err := Load(path)
+	require.NoError(t, err)
+
+	require.True(t, deleteByRef(t, first, "aaa"))
+	require.NoError(t, first.Save(0))
+
+	second.Entries = append(second.Entries, testEntry("ccc", onDay(3)))
+	require.NoError(t, second.Save(0))
+	require.Equal(t, []Entry{testEntry("ccc", onDay(3)), testEntry("bbb", onDay(2))}, second.Entries)
+}
+
+func TestFileStorage_InterleavedSavesByKey(t *testing.T) {
+	for key, want := range map[string][]string{
+		KeyCommit:      {"integration"},
+		KeyCommitLabel: {"integration", "unit"},
+	} {
+		t.Run(key, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history.json")
+			require.NoError(t, New(path).Save(0))
+
+			// two runners of one commit load the history before either saves.
+			first, err := Load(path)
+			require.NoError(t, err)
+			second, err := Load(path)
+			require.NoError(t, err)
+			require.NoError(t, first.SetKey(key))
+			require.NoError(t, second.SetKey(key))
+
+			first.Entries = append(first.Entries, testEntry("aaa", withLabel("unit"), onDay(1)))
+			require.NoError(t, first.Save(0))
+			second.Entries = append(second.Entries, testEntry("aaa", withLabel("integration"), onDay(2)))
+			require.NoError(t, second.Save(0))
+
+			loaded, err := Load(path)
+			require.NoError(t, err)
+			var labels []string
+			for _, entry := range loaded.Entries {
+				labels = append(labels, entry.Label)
+			}
+			require.Equal(t, want, labels)
+			if key == KeyCommit {
+				require.Equal(t, "integration", findByRef(t, loaded, "aaa").Label)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package history
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Series holds a coverage percentage over a window of entries, oldest first,
+// along with its summary statistics. Slope is the least-squares change in
+// percentage points per entry.
+type Series struct {
+	Values []float64 `json:"values" yaml:"values"`
+	First  float64   `json:"first"  yaml:"first"`
+	Last   float64   `json:"last"   yaml:"last"`
+	Min    float64   `json:"min"    yaml:"min"`
+	Max    float64   `json:"max"    yaml:"max"`
+	Slope  float64   `json:"slope"  yaml:"slope"`
+}
+
+// MetricTrend holds the Series of each coverage metric. A metric is nil when
+// no entry in the window has data for it, which is the case for lines in
+// history recorded before line coverage was tracked.
+type MetricTrend struct {
+	Statements *Series `json:"statements,omitempty" yaml:"statements,omitempty"`
+	Blocks     *Series `json:"blocks,omitempty"     yaml:"blocks,omitempty"`
+	Lines      *Series `json:"lines,omitempty"      yaml:"lines,omitempty"`
+}
+
+// PackageTrend is the MetricTrend of a single package.
+type PackageTrend struct {
+	Package     string `json:"package" yaml:"package"`
+	MetricTrend `yaml:",inline"`
+}
+
+// Trend holds the coverage trend over a window of history entries.
+type Trend struct {
+	From      time.Time      `json:"from"      yaml:"from"`
+	To        time.Time      `json:"to"        yaml:"to"`
+	Count     int            `json:"count"     yaml:"count"`
+	ByTotal   MetricTrend    `json:"byTotal"   yaml:"byTotal"`
+	ByPackage []PackageTrend `json:"byPackage" yaml:"byPackage"`
+	// Entries in the window, oldest first.
+	Entries []Entry `json:"-" yaml:"-"`
+}
+
+// NewTrend computes the Trend over entries, which are expected newest first as
+// stored in History. A package only contributes points for the entries it
+// appears in.
+func NewTrend(entries []Entry) *Trend {
+	window := slices.Clone(entries)
+	slices.Reverse(window)
+
+	t := &Trend{
+		Count:   len(window),
+		Entries: window,
+	}
+	if len(window) == 0 {
+		return t
+	}
+	t.From = window[0].Timestamp
+	t.To = window[len(window)-1].Timestamp
+
+	var stmts, blocks, lines []float64
+	type points struct{ stmts, blocks, lines []float64 }
+	byPackage := map[string]*points{}
+	var order []string
+	for _, e := range window {
+		total := e.Results.ByTotal
+		stmts = append(stmts, total.Statements.Percentage)
+		blocks = append(blocks, total.Blocks.Percentage)
+		if e.HasLines() {
+			lines = append(lines, total.Lines.Percentage)
+		}
+
+		for _, p := range e.Results.ByPackage {
+			pts, ok := byPackage[p.Package]
+			if !ok {
+				pts = new(points)
+				byPackage[p.Package] = pts
+				order = append(order, p.Package)
+			}
+			pts.stmts = append(pts.stmts, p.StatementPercentage)
+			pts.blocks = append(pts.blocks, p.BlockPercentage)
+			if e.HasLines() {
+				pts.lines = append(pts.lines, p.LinePercentage)
+			}
+		}
+	}
+	t.ByTotal = MetricTrend{
+		Statements: newSeries(stmts),
+		Blocks:     newSeries(blocks),
+		Lines:      newSeries(lines),
+	}
+
+	slices.Sort(order)
+	t.ByPackage = make([]PackageTrend, 0, len(order))
+	for _, name := range order {
+		pts := byPackage[name]
+		t.ByPackage = append(t.ByPackage, PackageTrend{
+			Package: name,
+			MetricTrend: MetricTrend{
+				Statements: newSeries(pts.stmts),
+				Blocks:     newSeries(pts.blocks),
+				Lines:      newSeries(pts.lines),
+			},
+		})
+	}
+	return t
+}
+
+func newSeries(values []float64) *Series {
+	if len(values) == 0 {
+		return nil
+	}
+	s := &Series{
+		Values: values,
+		First:  values[0],
+		Last:   values[len(values)-1],
+		Min:    slices.Min(values),
+		Max:    slices.Max(values),
+		Slope:  slope(values),
+	}
+	return s
+}
+
+// slope returns the least-squares slope of values against their index.
+func slope(values []float64) float64 {
+	n := float64(len(values))
+	if n < 2 { //nolint:mnd
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	// round away floating point noise so a flat series reports exactly 0.
+	return math.Round((n*sumXY-sumX*sumY)/denominator*1e6) / 1e6 //nolint:mnd
+}
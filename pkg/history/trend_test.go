@@ -0,0 +1,69 @@
+package history //nolint:testpackage
+
+import (
+	"testing"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/stretchr/testify/require"
+)
+
+func trendTotals(stmts, blocks float64, lines string, linePct float64) compute.Totals {
+	return compute.Totals{
+		Statements: compute.TotalStatements{Percentage: stmts},
+		Blocks:     compute.TotalBlocks{Percentage: blocks},
+		Lines:      compute.TotalLines{Coverage: lines, Percentage: linePct},
+	}
+}
+
+func TestNewTrend(t *testing.T) {
+	// newest first, as stored in History.
+	entries := []Entry{
+		testEntry("e40262964cc4", onDay(20), withResults(compute.Results{
+			ByPackage: []compute.ByPackage{
+				{Package: "b", By: compute.By{StatementPercentage: 40, Lines: "4/10", LinePercentage: 40}},
+				{Package: "a", By: compute.By{StatementPercentage: 70, Lines: "7/10", LinePercentage: 70}},
+			},
+			ByTotal: trendTotals(62, 55, "6/10", 60),
+		})),
+		testEntry("e40262964cc4", onDay(19), withResults(compute.Results{
+			ByPackage: []compute.ByPackage{
+				{Package: "a", By: compute.By{StatementPercentage: 75, Lines: "7/10", LinePercentage: 72}},
+			},
+			ByTotal: trendTotals(68, 57, "6/10", 66),
+		})),
+		testEntry("e40262964cc4", onDay(18), withResults(compute.Results{
+			ByPackage: []compute.ByPackage{{Package: "a", By: compute.By{StatementPercentage: 80}}},
+			ByTotal:   trendTotals(71, 58, "", 0),
+		})),
+	}
+
+	trend := NewTrend(entries)
+	require.Equal(t, 3, trend.Count)
+	require.Equal(t, entries[2].Timestamp, trend.From)
+	require.Equal(t, entries[0].Timestamp, trend.To)
+	require.Equal(t, entries[2].Timestamp, trend.Entries[0].Timestamp)
+
+	require.Equal(t, &Series{Values: []float64{71, 68, 62}, First: 71, Last: 62, Min: 62, Max: 71, Slope: -4.5},
+		trend.ByTotal.Statements)
+	require.Equal(t, []float64{58, 57, 55}, trend.ByTotal.Blocks.Values)
+	require.Equal(t, []float64{66, 60}, trend.ByTotal.Lines.Values)
+
+	require.Len(t, trend.ByPackage, 2)
+	a := trend.ByPackage[0]
+	require.Equal(t, "a", a.Package)
+	require.Equal(t, []float64{80, 75, 70}, a.Statements.Values)
+	require.InDelta(t, -5.0, a.Statements.Slope, 0)
+	require.Equal(t, []float64{72, 70}, a.Lines.Values)
+	b := trend.ByPackage[1]
+	require.Equal(t, "b", b.Package)
+	require.Equal(t, []float64{40}, b.Statements.Values)
+	require.Zero(t, b.Statements.Slope)
+	require.Equal(t, []float64{40}, b.Lines.Values)
+}
+
+func TestNewTrend_Empty(t *testing.T) {
+	trend := NewTrend(nil)
+	require.Zero(t, trend.Count)
+	require.Nil(t, trend.ByTotal.Statements)
+	require.Empty(t, trend.ByPackage)
+}
@@ -0,0 +1,142 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/config"
+)
+
+// SchemaVersion is the version of the history schema written by this
+// go-covercheck. History written without a version is version 1.
+//
+// Version 2 adds the version itself. Version 1 entries may predate the
+// coverage counts and line coverage, see migrateV1.
+const SchemaVersion = 2
+
+// ErrNewerSchemaVersion is returned when loading history written by a newer
+// go-covercheck with a schema this one does not know.
+var ErrNewerSchemaVersion = errors.New("history was written by a newer version of " + config.AppName)
+
+// migrations upgrade the entries of the schema version they are keyed by to
+// the next version.
+var migrations = map[int]func([]Entry) []Entry{
+	1: migrateV1,
+}
+
+// versionedEntry is an Entry as stored on its own, such as by dirStorage,
+// along with the schema version it was written with.
+type versionedEntry struct {
+	Version int `json:"version,omitempty"`
+	Entry
+}
+
+// encodeHistory encodes entries as a history document of the current schema
+// version.
+func encodeHistory(entries []Entry) ([]byte, error) {
+	return json.MarshalIndent(History{Version: SchemaVersion, Entries: entries}, "", "  ")
+}
+
+// decodeHistory decodes a history document and upgrades its entries to the
+// current schema version.
+func decodeHistory(data []byte) ([]Entry, error) {
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	return migrate(h.Entries, h.Version)
+}
+
+// encodeEntry encodes entry on its own, along with the current schema version.
+func encodeEntry(entry Entry) ([]byte, error) {
+	return json.MarshalIndent(versionedEntry{Version: SchemaVersion, Entry: entry}, "", "  ")
+}
+
+// decodeEntry decodes an entry encoded on its own and upgrades it to the
+// current schema version.
+func decodeEntry(data []byte) (Entry, error) {
+	var v versionedEntry
+	if err := json.Unmarshal(data, &v); err != nil {
+		return Entry{}, err
+	}
+	entries, err := migrate([]Entry{v.Entry}, v.Version)
+	if err != nil {
+		return Entry{}, err
+	}
+	return entries[0], nil
+}
+
+// migrate upgrades entries of schema version to the current one. A version of
+// 0 is version 1, which predates versioning.
+func migrate(entries []Entry, version int) ([]Entry, error) {
+	if version == 0 {
+		version = 1
+	}
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("%w: schema version %d is newer than the supported version %d, upgrade %s",
+			ErrNewerSchemaVersion, version, SchemaVersion, config.AppName)
+	}
+	for ; version < SchemaVersion; version++ {
+		entries = migrations[version](entries)
+	}
+	return entries, nil
+}
+
+// migrateV1 upgrades version 1 entries. Entries recorded before the coverage
+// counts were tracked only hold the "covered/total" fractions, which the counts
+// are parsed from. Entries recorded before line coverage was tracked have no
+// line totals; any line details of their files and packages are dropped, so an
+// entry holds line coverage for its totals, files, and packages or for none of
+// them, see Entry.HasLines.
+func migrateV1(entries []Entry) []Entry {
+	for i := range entries {
+		results := &entries[i].Results
+		lines := entries[i].HasLines()
+		if lines {
+			backfillCounts(results.ByTotal.Lines.Coverage, &results.ByTotal.Lines.Covered,
+				&results.ByTotal.Lines.Total)
+		} else {
+			results.ByTotal.Lines = compute.TotalLines{}
+		}
+		backfillCounts(results.ByTotal.Statements.Coverage, &results.ByTotal.Statements.Covered,
+			&results.ByTotal.Statements.Total)
+		backfillCounts(results.ByTotal.Blocks.Coverage, &results.ByTotal.Blocks.Covered,
+			&results.ByTotal.Blocks.Total)
+		for j := range results.ByFile {
+			migrateByV1(&results.ByFile[j].By, lines)
+		}
+		for j := range results.ByPackage {
+			migrateByV1(&results.ByPackage[j].By, lines)
+		}
+	}
+	return entries
+}
+
+// migrateByV1 backfills the coverage counts of by, and clears its line
+// coverage unless lines is set.
+func migrateByV1(by *compute.By, lines bool) {
+	backfillCounts(by.Statements, &by.StatementsCovered, &by.StatementsTotal)
+	backfillCounts(by.Blocks, &by.BlocksCovered, &by.BlocksTotal)
+	if lines {
+		backfillCounts(by.Lines, &by.LinesCovered, &by.LinesTotal)
+		return
+	}
+	by.Lines = ""
+	by.LinePercentage = 0
+	by.LineThreshold = 0
+	by.LinesCovered = 0
+	by.LinesTotal = 0
+}
+
+// backfillCounts sets covered and total from the coverage fraction when no
+// total was recorded.
+func backfillCounts(coverage string, covered, total *int) {
+	if *total != 0 {
+		return
+	}
+	if c, t, ok := compute.ParseCoverage(coverage); ok {
+		*covered, *total = c, t
+	}
+}
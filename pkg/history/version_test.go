@@ -0,0 +1,80 @@
+package history //nolint:testpackage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const legacyHistory = `{
+  "entries": [
+    {
+      "commit": "abc1234567890",
+      "branch": "main",
+      "timestamp": "2025-01-01T00:00:00Z",
+      "results": {
+        "byFile": [{"file": "a.go", "statementCoverage": "1/2", "lineCoverage": "0/0", "linesTotal": 0}],
+        "byTotal": {"statements": {"coverage": "1/2", "percentage": 50}, "lines": {"coverage": ""}}
+      }
+    }
+  ]
+}`
+
+func TestDecodeHistory_MigratesLegacy(t *testing.T) {
+	entries, err := decodeHistory([]byte(legacyHistory))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.False(t, entries[0].HasLines())
+	require.Empty(t, entries[0].Results.ByFile[0].Lines)
+	require.Equal(t, "1/2", entries[0].Results.ByFile[0].Statements)
+
+	// the counts are parsed from the coverage fractions
+	require.Equal(t, 1, entries[0].Results.ByFile[0].StatementsCovered)
+	require.Equal(t, 2, entries[0].Results.ByFile[0].StatementsTotal)
+	require.Equal(t, 1, entries[0].Results.ByTotal.Statements.Covered)
+	require.Equal(t, 2, entries[0].Results.ByTotal.Statements.Total)
+	require.Zero(t, entries[0].Results.ByFile[0].BlocksTotal)
+}
+
+func TestDecodeHistory_NewerVersion(t *testing.T) {
+	_, err := decodeHistory([]byte(`{"version": 99, "entries": []}`))
+	require.ErrorIs(t, err, ErrNewerSchemaVersion)
+	require.ErrorContains(t, err, "schema version 99 is newer than the supported version 2")
+
+	_, err = decodeEntry([]byte(`{"version": 99, "commit": "abc1234"}`))
+	require.ErrorIs(t, err, ErrNewerSchemaVersion)
+}
+
+func TestFileStorage_SaveWritesVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	require.NoError(t, os.WriteFile(path, []byte(legacyHistory), 0o600))
+
+	h, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, h.Save(0))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var saved History
+	require.NoError(t, json.Unmarshal(b, &saved))
+	require.Equal(t, SchemaVersion, saved.Version)
+	require.Len(t, saved.Entries, 1)
+}
+
+func TestDirStorage_RoundTripsVersion(t *testing.T) {
+	s := &dirStorage{path: t.TempDir()}
+	_, err := s.Save([]Entry{{Commit: "abc1234567890", Branch: "main"}}, KeyCommit, 0)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(s.path, "abc1234567890.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"version": 2`)
+
+	entries, err := s.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "abc1234567890", entries[0].Commit)
+}
@@ -6,6 +6,7 @@ package lines
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"slices"
 	"strconv"
@@ -312,7 +313,7 @@ func FormatUncoveredFromBlocks(blocks []Block) string {
 	}
 
 	slices.Sort(result)
-	return formatLineRanges(result)
+	return FormatLineRanges(result)
 }
 
 // CoverageFromBlocks returns the total and covered line counts for a
@@ -338,9 +339,9 @@ func CoverageFromBlocks(blocks []Block) (int, int) {
 	return len(allLines), len(coveredLines)
 }
 
-// formatLineRanges formats a slice of line numbers into a string of ranges.
-// For example, [1, 2, 3, 5, 6] becomes "1-3,5-6".
-func formatLineRanges(lines []int) string {
+// FormatLineRanges formats a sorted slice of line numbers into a string of
+// ranges. For example, [1, 2, 3, 5, 6] becomes "1-3,5-6".
+func FormatLineRanges(lines []int) string {
 	if len(lines) == 0 {
 		return ""
 	}
@@ -367,6 +368,33 @@ func formatLineRanges(lines []int) string {
 	return strings.Join(ranges, ",")
 }
 
+// ParseLineRanges parses a string of ranges formatted by FormatLineRanges into
+// the sorted line numbers it holds. For example, "1-3,5" becomes [1, 2, 3, 5].
+func ParseLineRanges(ranges string) ([]int, error) {
+	var lines []int
+	if strings.TrimSpace(ranges) == "" {
+		return lines, nil
+	}
+	for _, r := range strings.Split(ranges, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(r), "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line range %q", r)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, fmt.Errorf("invalid line range %q", r)
+			}
+		}
+		for ln := start; ln <= end; ln++ {
+			lines = append(lines, ln)
+		}
+	}
+	slices.Sort(lines)
+	return slices.Compact(lines), nil
+}
+
 // formatRange formats a single range of line numbers into a string.
 // If the start and end are the same, it returns a single number.
 func formatRange(start, end int) string {
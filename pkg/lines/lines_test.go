@@ -172,12 +172,29 @@ func TestFormatLineRanges(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatLineRanges(tt.lines)
+			result := FormatLineRanges(tt.lines)
 			require.Equal(t, tt.expected, result)
 		})
 	}
 }
 
+func TestParseLineRanges(t *testing.T) {
+	for ranges, expected := range map[string][]int{
+		"":             nil,
+		"5":            {5},
+		"1-3,5,7-8,10": {1, 2, 3, 5, 7, 8, 10},
+		"7-8, 1-2,8":   {1, 2, 7, 8},
+	} {
+		result, err := ParseLineRanges(ranges)
+		require.NoError(t, err, ranges)
+		require.Equal(t, expected, result, ranges)
+	}
+	for _, ranges := range []string{"a", "3-", "5-3", "1,,2"} {
+		_, err := ParseLineRanges(ranges)
+		require.Error(t, err, ranges)
+	}
+}
+
 func TestFormatRange(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -42,22 +42,42 @@ func resolveSyntaxStyle(name string) string {
 	return autoSyntaxStyle
 }
 
-func severityColor(actual, goal float64) func(a ...interface{}) string {
-	switch {
-	case goal <= 0 && actual <= 0:
-		return color.New(color.Reset).SprintFunc()
-	case goal <= 0:
-		return color.New(color.Reset).SprintFunc()
+// severity levels for an actual coverage value measured against its goal.
+const (
+	severityNone   = "none"
+	severityLow    = "low"
+	severityMedium = "medium"
+	severityHigh   = "high"
+)
+
+// severityLevel classifies actual against goal: low when actual is <= 50% of
+// the goal, medium when <= 99%, high otherwise, and none when there is no goal.
+func severityLevel(actual, goal float64) string {
+	if goal <= 0 {
+		return severityNone
 	}
 
 	pct := math.PercentFloat(actual, goal)
 	switch {
 	case pct <= 50: //nolint:mnd
-		return color.New(color.FgRed).SprintFunc()
+		return severityLow
 	case pct <= 99: //nolint:mnd
-		return color.New(color.FgYellow).SprintFunc()
+		return severityMedium
 	default:
+		return severityHigh
+	}
+}
+
+func severityColor(actual, goal float64) func(a ...interface{}) string {
+	switch severityLevel(actual, goal) {
+	case severityLow:
+		return color.New(color.FgRed).SprintFunc()
+	case severityMedium:
+		return color.New(color.FgYellow).SprintFunc()
+	case severityHigh:
 		return color.New(color.FgGreen).SprintFunc()
+	default:
+		return color.New(color.Reset).SprintFunc()
 	}
 }
 
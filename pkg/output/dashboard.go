@@ -0,0 +1,121 @@
+package output
+
+import (
+	_ "embed"
+	"html/template"
+	"os"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/history"
+)
+
+//go:embed dashboard.html.tmpl
+var dashboardTemplateText string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(dashboardTemplateText))
+
+// dashboardMetric is a totals card in the dashboard.
+type dashboardMetric struct {
+	Label      string
+	Coverage   string
+	Percentage float64
+	Threshold  float64
+	Failed     bool
+	Severity   string
+}
+
+// dashboardRow is a file or package row in the dashboard tables.
+type dashboardRow struct {
+	Name              string
+	By                compute.By
+	StatementSeverity string
+	BlockSeverity     string
+	LineSeverity      string
+	ShowUncovered     bool
+}
+
+type dashboardData struct {
+	Tool          string
+	Version       string
+	Failed        bool
+	ShowUncovered bool
+	Totals        []dashboardMetric
+	Files         []dashboardRow
+	Packages      []dashboardRow
+	Comparison    *history.Comparison
+}
+
+// renderDashboard writes a self-contained html page with totals cards and
+// sortable, filterable file and package tables. Styles and scripts are inlined
+// so the page works when opened straight from a CI artifact. The page shows
+// the run as failed when hasFailure is set, and lists the regressions and new
+// files of the comparison, when not nil, that failed their gate; the deltas of
+// the comparison are left out.
+func renderDashboard(results compute.Results, comparison *history.Comparison, cfg *config.Config,
+	hasFailure bool) {
+	err := dashboardTemplate.Execute(os.Stdout, newDashboardData(results, comparison, cfg, hasFailure))
+	bailOnError(err)
+}
+
+func newDashboardData(results compute.Results, comparison *history.Comparison, cfg *config.Config,
+	hasFailure bool) dashboardData {
+	t := results.ByTotal
+	data := dashboardData{
+		Tool:          config.AppName,
+		Version:       config.AppVersion,
+		Failed:        hasFailure || t.Statements.Failed || t.Blocks.Failed || t.Lines.Failed,
+		Comparison:    comparison,
+		ShowUncovered: !cfg.NoUncoveredLines,
+		Totals: []dashboardMetric{
+			{
+				Label:      "Statements",
+				Coverage:   t.Statements.Coverage,
+				Percentage: t.Statements.Percentage,
+				Threshold:  t.Statements.Threshold,
+				Failed:     t.Statements.Failed,
+				Severity:   severityLevel(t.Statements.Percentage, t.Statements.Threshold),
+			},
+			{
+				Label:      "Blocks",
+				Coverage:   t.Blocks.Coverage,
+				Percentage: t.Blocks.Percentage,
+				Threshold:  t.Blocks.Threshold,
+				Failed:     t.Blocks.Failed,
+				Severity:   severityLevel(t.Blocks.Percentage, t.Blocks.Threshold),
+			},
+			{
+				Label:      "Lines",
+				Coverage:   t.Lines.Coverage,
+				Percentage: t.Lines.Percentage,
+				Threshold:  t.Lines.Threshold,
+				Failed:     t.Lines.Failed,
+				Severity:   severityLevel(t.Lines.Percentage, t.Lines.Threshold),
+			},
+		},
+		Files:    make([]dashboardRow, 0, len(results.ByFile)),
+		Packages: make([]dashboardRow, 0, len(results.ByPackage)),
+	}
+
+	for _, r := range results.ByFile {
+		data.Failed = data.Failed || r.Failed
+		row := newDashboardRow(r.File, r.By)
+		row.ShowUncovered = data.ShowUncovered
+		data.Files = append(data.Files, row)
+	}
+	for _, r := range results.ByPackage {
+		data.Failed = data.Failed || r.Failed
+		data.Packages = append(data.Packages, newDashboardRow(r.Package, r.By))
+	}
+	return data
+}
+
+func newDashboardRow(name string, by compute.By) dashboardRow {
+	return dashboardRow{
+		Name:              name,
+		By:                by,
+		StatementSeverity: severityLevel(by.StatementPercentage, by.StatementThreshold),
+		BlockSeverity:     severityLevel(by.BlockPercentage, by.BlockThreshold),
+		LineSeverity:      severityLevel(by.LinePercentage, by.LineThreshold),
+	}
+}
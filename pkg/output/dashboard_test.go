@@ -0,0 +1,120 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/history"
+	"github.com/mach6/go-covercheck/pkg/output"
+	"github.com/mach6/go-covercheck/pkg/test"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/cover"
+)
+
+func TestFormatAndReport_Dashboard(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatDashboard
+	cfg.NoColor = true
+
+	profiles := []*cover.Profile{
+		{
+			FileName: "pkg/good/good.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 4, Count: 1},
+			},
+		},
+		{
+			FileName: "pkg/bad/<bad>.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 1, Count: 1},
+				{NumStmt: 9, Count: 0},
+			},
+		},
+	}
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		results, failed := compute.CollectResults(profiles, cfg)
+		require.True(t, failed)
+		output.FormatAndReport(results, cfg, failed)
+	})
+	require.Empty(t, stderr)
+
+	require.True(t, strings.HasPrefix(stdout, "<!DOCTYPE html>"))
+	require.Contains(t, stdout, "✘ Coverage check failed")
+	require.Contains(t, stdout, `<input type="search" id="search"`)
+	require.Contains(t, stdout, `<table class="sortable" id="files">`)
+	require.Contains(t, stdout, `<table class="sortable" id="packages">`)
+	require.Contains(t, stdout, `<td>pkg/good/good.go</td>`)
+	require.Contains(t, stdout, `<td>pkg/good</td>`)
+	require.Contains(t, stdout, `<td data-value="100" class="sev-high">100.0</td>`)
+	require.Contains(t, stdout, `<td data-value="10" class="sev-low">10.0</td>`)
+	require.Contains(t, stdout, `<div class="pct sev-medium">35.7%</div>`)
+	require.Contains(t, stdout, "&lt;bad&gt;.go")
+	require.NotContains(t, stdout, "<bad>")
+
+	// fully self-contained: no external scripts, styles, or links.
+	require.NotContains(t, stdout, "src=")
+	require.NotContains(t, stdout, "href=")
+	// summary output would corrupt the document.
+	require.NotContains(t, stdout, "required for")
+}
+
+func TestFormatAndReport_Dashboard_NoUncoveredLines(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatDashboard
+	cfg.NoUncoveredLines = true
+
+	profiles := []*cover.Profile{
+		{
+			FileName: "main.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 4, Count: 1},
+			},
+		},
+	}
+
+	stdout, _ := test.RepipeStdOutAndErrForTest(func() {
+		results, failed := compute.CollectResults(profiles, cfg)
+		output.FormatAndReport(results, cfg, failed)
+	})
+	require.Contains(t, stdout, "✔ All good")
+	require.NotContains(t, stdout, "Uncovered Lines")
+}
+
+func TestFormatAndReportWithComparison_DashboardRegression(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatDashboard
+
+	profiles := []*cover.Profile{
+		{
+			FileName: "main.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 4, Count: 1},
+			},
+		},
+	}
+	comparison := &history.Comparison{
+		Ref: "main",
+		Regressions: []history.Regression{{
+			Scope:  config.RegressionScopeTotal,
+			Name:   "total",
+			Metric: config.StatementsSection,
+			Delta:  history.Delta{Old: 100, New: 90, Delta: -10},
+		}},
+	}
+
+	stdout, _ := test.RepipeStdOutAndErrForTest(func() {
+		results, failed := compute.CollectResults(profiles, cfg)
+		require.False(t, failed)
+		output.FormatAndReportWithComparison(results, comparison, nil, cfg, comparison.Failed())
+	})
+	require.Contains(t, stdout, "✘ Coverage check failed")
+	require.NotContains(t, stdout, "✔ All good")
+	require.Contains(t, stdout, "<h2>Regressions against main</h2>")
+	require.Contains(t, stdout, `<td class="sev-low">-10.0</td>`)
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"time"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/history"
+)
+
+// EnvelopeSchemaVersion is the version of the Envelope layout. Bump it when
+// fields are renamed or removed so consumers can detect incompatible output.
+const EnvelopeSchemaVersion = 1
+
+// Envelope wraps structured results with the metadata of the run that
+// produced them, so stored json/yaml artifacts stay self-describing.
+type Envelope struct {
+	SchemaVersion int                 `json:"schemaVersion"        yaml:"schemaVersion"`
+	Metadata      Metadata            `json:"metadata"             yaml:"metadata"`
+	Results       compute.Results     `json:"results"              yaml:"results"`
+	Comparison    *history.Comparison `json:"comparison,omitempty" yaml:"comparison,omitempty"`
+}
+
+// Metadata holds details about a go-covercheck run.
+type Metadata struct {
+	Tool        string     `json:"tool"                  yaml:"tool"`
+	Version     string     `json:"version"               yaml:"version"`
+	Revision    string     `json:"revision"              yaml:"revision"`
+	Timestamp   time.Time  `json:"timestamp"             yaml:"timestamp"`
+	Commit      string     `json:"commit"                yaml:"commit"`
+	Branch      string     `json:"branch"                yaml:"branch"`
+	Tags        []string   `json:"tags,omitempty"        yaml:"tags,omitempty"`
+	ProfileMode string     `json:"profileMode,omitempty" yaml:"profileMode,omitempty"`
+	ConfigPath  string     `json:"configPath,omitempty"  yaml:"configPath,omitempty"`
+	ModuleName  string     `json:"moduleName,omitempty"  yaml:"moduleName,omitempty"`
+	DiffFrom    string     `json:"diffFrom,omitempty"    yaml:"diffFrom,omitempty"`
+	Thresholds  Thresholds `json:"thresholds"            yaml:"thresholds"`
+}
+
+// Thresholds holds the effective thresholds applied during a run.
+type Thresholds struct {
+	Statements float64                     `json:"statements" yaml:"statements"`
+	Blocks     float64                     `json:"blocks"     yaml:"blocks"`
+	Lines      float64                     `json:"lines"      yaml:"lines"`
+	Total      config.PerOverride          `json:"total"      yaml:"total"`
+	PerFile    config.PerThresholdOverride `json:"perFile"    yaml:"perFile"`
+	PerPackage config.PerThresholdOverride `json:"perPackage" yaml:"perPackage"`
+}
+
+// NewMetadata collects Metadata for the current run. The git details are
+// detected from repoPath the same way history entries are recorded.
+func NewMetadata(cfg *config.Config, repoPath, profileMode, moduleName string) Metadata {
+	info := history.DetectGitInfo(repoPath)
+	return Metadata{
+		Tool:        config.AppName,
+		Version:     config.AppVersion,
+		Revision:    config.AppRevision,
+		Timestamp:   time.Now().UTC(),
+		Commit:      info.Commit,
+		Branch:      info.Branch,
+		Tags:        info.Tags,
+		ProfileMode: profileMode,
+		ConfigPath:  cfg.ConfigPath,
+		ModuleName:  moduleName,
+		DiffFrom:    cfg.DiffFrom,
+		Thresholds: Thresholds{
+			Statements: cfg.StatementThreshold,
+			Blocks:     cfg.BlockThreshold,
+			Lines:      cfg.LineThreshold,
+			Total:      cfg.Total,
+			PerFile:    cfg.PerFile,
+			PerPackage: cfg.PerPackage,
+		},
+	}
+}
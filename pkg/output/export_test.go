@@ -9,6 +9,7 @@ var (
 	TrimWithEllipsis     = trimWithEllipsis
 	ApplyTableWidths     = applyTableWidths
 	MatchesInspectFile   = matchesInspectFile
+	RenderComparison     = renderComparison
 )
 
 const FixedColumnWidth = fixedColumnWidth
@@ -1,9 +1,13 @@
 package output
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -47,141 +51,354 @@ func getHistoryTableStyle(cfg *config.Config) table.Style {
 	}
 }
 
-// CompareHistory shows the comparison output for a ref: and the results.
-func CompareHistory(ref string, refEntry *history.Entry, results compute.Results) {
-	fmt.Printf("\n≡ Comparing against ref: %s [commit %s]\n",
-		color.New(color.FgBlue).Sprint(ref),
-		color.New(color.FgHiBlack).Sprint(refEntry.Commit[:7]),
-	)
+// renderComparison prints the comparison as text, one line per changed metric.
+func renderComparison(c *history.Comparison) {
+	switch {
+	case c.BestOf > 0:
+		fmt.Printf("\n≡ Comparing against the %s of %d history entries\n",
+			color.New(color.FgBlue).Sprint(c.Ref), c.BestOf)
+	case c.Target != nil:
+		fmt.Printf("\n≡ Comparing ref: %s [commit %s] against ref: %s [commit %s]\n",
+			color.New(color.FgBlue).Sprint(c.Target.Ref),
+			color.New(color.FgHiBlack).Sprint(history.QualifiedRef(c.Target.Commit, c.Target.Label)),
+			color.New(color.FgBlue).Sprint(c.Ref),
+			color.New(color.FgHiBlack).Sprint(history.QualifiedRef(c.Commit, c.Label)),
+		)
+	default:
+		fmt.Printf("\n≡ Comparing against ref: %s [commit %s]\n",
+			color.New(color.FgBlue).Sprint(c.Ref),
+			color.New(color.FgHiBlack).Sprint(history.QualifiedRef(c.Commit, c.Label)),
+		)
+	}
 
-	bPrintedFile := compareByFile(results, refEntry)
-	bPrintedPkg := compareByPackage(results, refEntry)
-	bPrintedTotal := compareByTotal(results, refEntry)
+	bPrintedFile := false
+	for _, d := range c.ByFile {
+		bPrintedFile = compareShowDeltas(" → By File", d.File, d.Deltas, bPrintedFile)
+	}
+	bPrintedPkg := false
+	for _, d := range c.ByPackage {
+		bPrintedPkg = compareShowDeltas(" → By Package", d.Package, d.Deltas, bPrintedPkg)
+	}
+	bPrintedTotal := compareShowDeltas(" → By Total", "total", c.ByTotal, false)
+	bPrintedAdded := compareShowAddedFiles(c)
+	removedLines := c.ByTotal.Lines != nil
+	bPrintedRemoved := compareShowEntries(" → Removed Files", "−", color.FgRed,
+		fileEntries(c.RemovedFiles, removedLines))
+	bPrintedAdded = compareShowEntries(" → New Packages", "+", color.FgGreen,
+		packageEntries(c.AddedPackages, true)) || bPrintedAdded
+	bPrintedRemoved = compareShowEntries(" → Removed Packages", "−", color.FgRed,
+		packageEntries(c.RemovedPackages, removedLines)) || bPrintedRemoved
+	bPrintedLines := compareShowLines(c.Lines)
 
-	if !bPrintedTotal && !bPrintedPkg && !bPrintedFile {
+	if !bPrintedTotal && !bPrintedPkg && !bPrintedFile && !bPrintedAdded && !bPrintedRemoved && !bPrintedLines {
 		fmt.Println(" → No change")
 	}
 }
 
-//nolint:dupl,gocognit,cyclop // structurally parallel to compareByFile but operates on different types
-func compareByPackage(results compute.Results, refEntry *history.Entry) bool {
-	// ByPackage
-	bPrintedPkg := false
-	for _, curr := range results.ByPackage {
-		for _, prev := range refEntry.Results.ByPackage {
-			if curr.Package == prev.Package { //nolint:nestif
-				s, ss := formatDelta(curr.StatementPercentage - prev.StatementPercentage)
-				b, sb := formatDelta(curr.BlockPercentage - prev.BlockPercentage)
-				// Handle case where historical data might not have line coverage
-				// Only show line comparison if historical data has line coverage fields
-				l, sl := "", false
-				if prev.Lines != "" {
-					l, sl = formatDelta(curr.LinePercentage - prev.LinePercentage)
-				}
-				if ss || sb || sl {
-					if !bPrintedPkg {
-						fmt.Printf(" → By Package\n")
-						bPrintedPkg = true
-					}
-
-					if ss {
-						compareShowS()
-						fmt.Printf("%s [%s]\n", curr.Package, s)
-					}
-					if sb {
-						compareShowB()
-						fmt.Printf("%s [%s]\n", curr.Package, b)
-					}
-					if sl {
-						compareShowL()
-						fmt.Printf("%s [%s]\n", curr.Package, l)
-					}
-				}
-			}
+// comparedEntry is a file or package that exists on one side of a comparison,
+// along with whether that side holds line coverage. The compared entry does
+// not when it was recorded before line coverage was tracked, in which case the
+// Comparison has no total line Delta.
+type comparedEntry struct {
+	name  string
+	by    compute.By
+	lines bool
+}
+
+func fileEntries(files []compute.ByFile, lines bool) []comparedEntry {
+	entries := make([]comparedEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, comparedEntry{name: f.File, by: f.By, lines: lines})
+	}
+	return entries
+}
+
+func packageEntries(packages []compute.ByPackage, lines bool) []comparedEntry {
+	entries := make([]comparedEntry, 0, len(packages))
+	for _, p := range packages {
+		entries = append(entries, comparedEntry{name: p.Package, by: p.By, lines: lines})
+	}
+	return entries
+}
+
+// compareShowAddedFiles prints the files added since the compared ref, marking
+// those below the new file threshold.
+func compareShowAddedFiles(c *history.Comparison) bool {
+	if len(c.AddedFiles) == 0 {
+		return false
+	}
+
+	failed := make(map[string]bool, len(c.FailedNewFiles))
+	for _, f := range c.FailedNewFiles {
+		failed[f] = true
+	}
+
+	fmt.Println(" → New Files")
+	for _, f := range c.AddedFiles {
+		if !failed[f.File] {
+			fmt.Printf("    [%s] %s %s\n", color.New(color.FgGreen).Sprint("+"), f.File, formatCoverage(f.By, true))
+			continue
 		}
+		fmt.Printf("    [%s] %s %s [below %s new file threshold]\n",
+			color.New(color.FgRed).Sprint("✘"), f.File, formatCoverage(f.By, true),
+			color.New(color.FgCyan).Sprintf("%.1f%%", c.NewFileThreshold),
+		)
 	}
-	return bPrintedPkg
+	return true
 }
 
-func compareByTotal(results compute.Results, refEntry *history.Entry) bool {
-	// Totals
-	bPrintedTotal := false
-	deltaS, okS := formatDelta(results.ByTotal.Statements.Percentage - refEntry.Results.ByTotal.Statements.Percentage)
-	deltaB, okB := formatDelta(results.ByTotal.Blocks.Percentage - refEntry.Results.ByTotal.Blocks.Percentage)
-	// Handle case where historical data might not have line coverage
-	// Only show line comparison if historical data has line coverage fields
-	deltaL, okL := "", false
-	if refEntry.Results.ByTotal.Lines.Coverage != "" {
-		deltaL, okL = formatDelta(results.ByTotal.Lines.Percentage - refEntry.Results.ByTotal.Lines.Percentage)
-	}
-
-	if okS || okB || okL {
-		fmt.Printf(" → By Total\n")
-		bPrintedTotal = true
-		if okS {
-			compareShowS()
-			fmt.Printf("total [%s]\n", deltaS)
+// compareShowEntries prints entries under heading with a colored marker.
+func compareShowEntries(heading, marker string, markerColor color.Attribute, entries []comparedEntry) bool {
+	if len(entries) == 0 {
+		return false
+	}
+
+	fmt.Println(heading)
+	for _, e := range entries {
+		fmt.Printf("    [%s] %s %s\n", color.New(markerColor).Sprint(marker), e.name, formatCoverage(e.by, e.lines))
+	}
+	return true
+}
+
+// compareShowLines prints the line ranges of each file that lost or gained
+// coverage since the compared ref.
+func compareShowLines(changes []history.LineChange) bool {
+	bPrinted := false
+	sections := []struct {
+		heading     string
+		marker      string
+		markerColor color.Attribute
+		ranges      func(history.LineChange) string
+	}{
+		{" → Newly Uncovered Lines", "−", color.FgRed, func(l history.LineChange) string { return l.NewlyUncovered }},
+		{" → Newly Covered Lines", "+", color.FgGreen, func(l history.LineChange) string { return l.NewlyCovered }},
+	}
+	for _, section := range sections {
+		bPrintedHeading := false
+		for _, l := range changes {
+			ranges := section.ranges(l)
+			if ranges == "" {
+				continue
+			}
+			if !bPrintedHeading {
+				fmt.Println(section.heading)
+				bPrintedHeading = true
+			}
+			fmt.Printf("    [%s] %s %s\n", color.New(section.markerColor).Sprint(section.marker), l.File,
+				color.New(color.FgHiBlack).Sprint(ranges))
 		}
-		if okB {
-			compareShowB()
-			fmt.Printf("total [%s]\n", deltaB)
+		bPrinted = bPrinted || bPrintedHeading
+	}
+	return bPrinted
+}
+
+// formatCoverage formats the statement, block, and line percentages of by,
+// colored by severity. Lines are omitted unless lines is set.
+func formatCoverage(by compute.By, lines bool) string {
+	s := fmt.Sprintf("[%s %s] [%s %s]",
+		color.New(color.FgCyan).Sprint("S"),
+		severityColor(by.StatementPercentage, by.StatementThreshold)(fmt.Sprintf("%.1f%%", by.StatementPercentage)),
+		color.New(color.FgHiMagenta).Sprint("B"),
+		severityColor(by.BlockPercentage, by.BlockThreshold)(fmt.Sprintf("%.1f%%", by.BlockPercentage)),
+	)
+	if lines {
+		s += fmt.Sprintf(" [%s %s]",
+			color.New(color.FgYellow).Sprint("L"),
+			severityColor(by.LinePercentage, by.LineThreshold)(fmt.Sprintf("%.1f%%", by.LinePercentage)),
+		)
+	}
+	return s
+}
+
+// compareShowDeltas prints the changed metrics of name, preceded by heading
+// unless it was already printed. It returns whether heading has been printed.
+func compareShowDeltas(heading, name string, d history.Deltas, bPrintedHeading bool) bool {
+	s, ss := formatDelta(d.Statements.Delta)
+	b, sb := formatDelta(d.Blocks.Delta)
+	l, sl := "", false
+	if d.Lines != nil {
+		l, sl = formatDelta(d.Lines.Delta)
+	}
+	if !ss && !sb && !sl {
+		return bPrintedHeading
+	}
+
+	if !bPrintedHeading {
+		fmt.Println(heading)
+	}
+	if ss {
+		compareShowS()
+		fmt.Printf("%s [%s]\n", name, s)
+	}
+	if sb {
+		compareShowB()
+		fmt.Printf("%s [%s]\n", name, b)
+	}
+	if sl {
+		compareShowL()
+		fmt.Printf("%s [%s]\n", name, l)
+	}
+	return true
+}
+
+// renderComparisonTable renders the comparison as a md, html, csv, or tsv
+// table with one row per changed metric, per metric of each new or removed
+// file and package, and per file with newly uncovered or covered lines, whose
+// line ranges are given in the Delta column.
+func renderComparisonTable(c *history.Comparison, cfg *config.Config) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(getTableStyle(cfg))
+	t.AppendHeader(table.Row{"Ref", "Scope", "Name", "Metric", "Old %", "New %", "Delta"})
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Ref", Align: text.AlignLeft},
+		{Name: "Scope", Align: text.AlignLeft},
+		{Name: "Name", Align: text.AlignLeft},
+		{Name: "Metric", Align: text.AlignLeft},
+	})
+
+	appendRows := func(scope, name string, d history.Deltas) {
+		metrics := []struct {
+			name  string
+			delta *history.Delta
+		}{
+			{"statements", &d.Statements},
+			{"blocks", &d.Blocks},
+			{"lines", d.Lines},
 		}
-		if okL {
-			compareShowL()
-			fmt.Printf("total [%s]\n", deltaL)
+		for _, m := range metrics {
+			if m.delta == nil || m.delta.Delta == 0 {
+				continue
+			}
+			t.AppendRow(table.Row{
+				c.Ref, scope, name, m.name,
+				fmt.Sprintf("%.1f", m.delta.Old),
+				fmt.Sprintf("%.1f", m.delta.New),
+				fmt.Sprintf("%+.1f", m.delta.Delta),
+			})
 		}
 	}
-	return bPrintedTotal
-}
+	for _, d := range c.ByFile {
+		appendRows("file", d.File, d.Deltas)
+	}
+	for _, d := range c.ByPackage {
+		appendRows("package", d.Package, d.Deltas)
+	}
+	appendRows("total", "total", c.ByTotal)
 
-//nolint:dupl,gocognit,cyclop // structurally parallel to compareByPackage but operates on different types
-func compareByFile(results compute.Results, refEntry *history.Entry) bool {
-	// ByFile
-	bPrintedFile := false
-	for _, curr := range results.ByFile {
-		for _, prev := range refEntry.Results.ByFile {
-			if curr.File == prev.File { //nolint:nestif
-				s, ss := formatDelta(curr.StatementPercentage - prev.StatementPercentage)
-				b, sb := formatDelta(curr.BlockPercentage - prev.BlockPercentage)
-				// Handle case where historical data might not have line coverage
-				// Only show line comparison if historical data has line coverage fields
-				l, sl := "", false
-				if prev.Lines != "" {
-					l, sl = formatDelta(curr.LinePercentage - prev.LinePercentage)
+	appendEntryRows := func(scope, change string, entries []comparedEntry) {
+		for _, e := range entries {
+			metrics := []struct {
+				name string
+				pct  float64
+			}{
+				{"statements", e.by.StatementPercentage},
+				{"blocks", e.by.BlockPercentage},
+				{"lines", e.by.LinePercentage},
+			}
+			for _, m := range metrics {
+				if m.name == "lines" && !e.lines {
+					continue
 				}
-				if ss || sb || sl {
-					if !bPrintedFile {
-						fmt.Printf(" → By File\n")
-						bPrintedFile = true
-					}
-
-					if ss {
-						compareShowS()
-						fmt.Printf("%s [%s]\n", curr.File, s)
-					}
-					if sb {
-						compareShowB()
-						fmt.Printf("%s [%s]\n", curr.File, b)
-					}
-					if sl {
-						compareShowL()
-						fmt.Printf("%s [%s]\n", curr.File, l)
-					}
+				pct := fmt.Sprintf("%.1f", m.pct)
+				if change == "new" {
+					t.AppendRow(table.Row{c.Ref, scope, e.name, m.name, "", pct, change})
+				} else {
+					t.AppendRow(table.Row{c.Ref, scope, e.name, m.name, pct, "", change})
 				}
 			}
 		}
 	}
-	return bPrintedFile
+	appendEntryRows("file", "new", fileEntries(c.AddedFiles, true))
+	appendEntryRows("file", "removed", fileEntries(c.RemovedFiles, c.ByTotal.Lines != nil))
+	appendEntryRows("package", "new", packageEntries(c.AddedPackages, true))
+	appendEntryRows("package", "removed", packageEntries(c.RemovedPackages, c.ByTotal.Lines != nil))
+
+	for _, l := range c.Lines {
+		if l.NewlyUncovered != "" {
+			t.AppendRow(table.Row{c.Ref, "file", l.File, "newly uncovered lines", "", "", l.NewlyUncovered})
+		}
+		if l.NewlyCovered != "" {
+			t.AppendRow(table.Row{c.Ref, "file", l.File, "newly covered lines", "", "", l.NewlyCovered})
+		}
+	}
+
+	renderWriter(t, cfg)
+}
+
+// ReportComparison writes out a comparison between two history entries in
+// the selected format. json and yaml encode the comparison as the document,
+// and ndjson as a single comparison record.
+func ReportComparison(c *history.Comparison, cfg *config.Config) {
+	switch cfg.Format {
+	case config.FormatTable:
+		renderComparison(c)
+	case config.FormatMD, config.FormatHTML, config.FormatCSV, config.FormatTSV:
+		renderComparisonTable(c, cfg)
+	case config.FormatJSON, config.FormatYAML:
+		writeStructured(c, cfg)
+	case config.FormatNDJSON:
+		err := json.NewEncoder(os.Stdout).Encode(NDJSONComparison{Type: NDJSONTypeComparison, Comparison: c})
+		bailOnError(err)
+	default:
+		bailOnError(errors.New(color.RedString("Unsupported format for history comparison: %s", cfg.Format)))
+	}
 }
 
-// ShowHistory displays a summary table of the history entries.
+// ShowHistory displays the history entries in the selected format. The table
+// format shows a summary table, json and yaml the entries as a document,
+// ndjson one record per entry, csv and tsv a flat time series with one row per
+// entry and scope, and md and html one summary table with a row per entry.
 func ShowHistory(h *history.History, limit int, cfg *config.Config) {
 	count := limit
 	if count <= 0 || count > len(h.Entries) {
 		count = len(h.Entries)
 	}
+	entries := h.Entries[:count]
 
-	if len(h.Entries) == 0 || count == 0 {
+	switch cfg.Format {
+	case config.FormatJSON, config.FormatYAML:
+		writeStructured(history.History{Entries: entries}, cfg)
+	case config.FormatNDJSON:
+		enc := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			bailOnError(enc.Encode(NDJSONEntry{Type: NDJSONTypeEntry, Entry: entry}))
+		}
+	case config.FormatCSV, config.FormatTSV:
+		renderHistorySeries(entries, cfg)
+	case config.FormatMD, config.FormatHTML:
+		renderHistorySummary(entries, cfg)
+	case config.FormatDashboard:
+		bailOnError(errors.New(color.RedString("Unsupported format for history: %s", cfg.Format)))
+	default:
+		renderHistoryTable(entries, cfg)
+	}
+}
+
+// ShowPrunedHistory shows the history entries removed by pruning, or that would
+// be removed when dryRun is set. The table format lists one line per entry,
+// other formats show the entries as ShowHistory does.
+func ShowPrunedHistory(removed []history.Entry, dryRun bool, cfg *config.Config) {
+	if cfg.Format != config.FormatTable {
+		ShowHistory(&history.History{Entries: removed}, 0, cfg)
+		return
+	}
+
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	for _, entry := range removed {
+		fmt.Printf("    [%s] %s [%s %s]\n", color.New(color.FgRed).Sprint("−"), entry.Ref(), entry.Branch,
+			entry.Timestamp.Format(time.DateOnly))
+	}
+	fmt.Printf("≡ %s %d history entr%s\n", verb, len(removed),
+		map[bool]string{true: "y", false: "ies"}[len(removed) == 1])
+}
+
+func renderHistoryTable(entries []history.Entry, cfg *config.Config) {
+	count := len(entries)
+	if count == 0 {
 		fmt.Printf("≡ No history entries to show\n")
 		return
 	}
@@ -190,7 +407,12 @@ func ShowHistory(h *history.History, limit int, cfg *config.Config) {
 	t.SetOutputMirror(os.Stdout)
 	t.SetStyle(getHistoryTableStyle(cfg))
 
-	t.AppendHeader(table.Row{"Timestamp", "Commit", "Branch", "Tags", "Label", "Coverage"})
+	builds := hasBuilds(entries)
+	header := table.Row{"Timestamp", "Commit", "Branch", "Tags", "Label"}
+	if builds {
+		header = append(header, "Build")
+	}
+	t.AppendHeader(append(header, "Coverage"))
 
 	t.SetColumnConfigs([]table.ColumnConfig{
 		{Name: "Timestamp", Align: text.AlignLeft},
@@ -198,12 +420,11 @@ func ShowHistory(h *history.History, limit int, cfg *config.Config) {
 		{Name: "Branch", Align: text.AlignLeft},
 		{Name: "Tags", Align: text.AlignLeft},
 		{Name: "Label", Align: text.AlignLeft},
+		{Name: "Build", Align: text.AlignLeft},
 		{Name: "Coverage", Align: text.AlignLeft},
 	})
 
-	for i := range count {
-		entry := h.Entries[i]
-
+	for _, entry := range entries {
 		stmtColor := severityColor(entry.Results.ByTotal.Statements.Percentage,
 			entry.Results.ByTotal.Statements.Threshold)
 		blockColor := severityColor(entry.Results.ByTotal.Blocks.Percentage,
@@ -216,18 +437,21 @@ func ShowHistory(h *history.History, limit int, cfg *config.Config) {
 		// Build coverage display string - show line coverage only if data exists
 		coverageDisplay := stmtColor(fmt.Sprintf("%-7s", entry.Results.ByTotal.Statements.Coverage)) + " [S]\n" +
 			blockColor(fmt.Sprintf("%-7s", entry.Results.ByTotal.Blocks.Coverage)) + " [B]"
-		if entry.Results.ByTotal.Lines.Coverage != "" {
+		if entry.HasLines() {
 			coverageDisplay += "\n" + lineColor(fmt.Sprintf("%-7s", entry.Results.ByTotal.Lines.Coverage)) + " [L]"
 		}
 
-		t.AppendRow(table.Row{
+		row := table.Row{
 			fmt.Sprintf("%-10s", entry.Timestamp.Format("2006-01-02")),
-			fmt.Sprintf("%-7s", entry.Commit[:7]),
+			fmt.Sprintf("%-7s", entry.Ref()),
 			fmt.Sprintf("%-15s", entry.Branch),
 			fmt.Sprintf("%-15s", wrapText(strings.Join(entry.Tags, ", "), wrapTextWidth)),
 			wrapText(fmt.Sprintf("%-15s", entry.Label), wrapTextWidth),
-			coverageDisplay,
-		})
+		}
+		if builds {
+			row = append(row, wrapText(entry.Build.Summary(), wrapTextWidth))
+		}
+		t.AppendRow(append(row, coverageDisplay))
 	}
 
 	t.Render()
@@ -237,6 +461,130 @@ func ShowHistory(h *history.History, limit int, cfg *config.Config) {
 		map[bool]string{true: "y", false: "ies"}[count == 1])
 }
 
+// renderHistorySummary renders one row per entry with the total coverage of
+// each metric, for markdown and html documents.
+func renderHistorySummary(entries []history.Entry, cfg *config.Config) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(getTableStyle(cfg))
+	builds := hasBuilds(entries)
+	header := table.Row{"Timestamp", "Commit", "Branch", "Tags", "Label"}
+	if builds {
+		header = append(header, "Build", "Build URL")
+	}
+	t.AppendHeader(append(header, "Statements", "Blocks", "Lines"))
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Timestamp", Align: text.AlignLeft},
+		{Name: "Commit", Align: text.AlignLeft},
+		{Name: "Branch", Align: text.AlignLeft},
+		{Name: "Tags", Align: text.AlignLeft},
+		{Name: "Label", Align: text.AlignLeft},
+		{Name: "Build", Align: text.AlignLeft},
+		{Name: "Build URL", Align: text.AlignLeft},
+	})
+
+	for _, entry := range entries {
+		total := entry.Results.ByTotal
+		lines := ""
+		if entry.HasLines() {
+			lines = fmt.Sprintf("%s (%.1f%%)", total.Lines.Coverage, total.Lines.Percentage)
+		}
+		row := table.Row{
+			entry.Timestamp.Format(time.RFC3339),
+			entry.Ref(),
+			entry.Branch,
+			strings.Join(entry.Tags, ", "),
+			entry.Label,
+		}
+		if builds {
+			row = append(row, entry.Build.Summary(), entry.Build.Field("url"))
+		}
+		t.AppendRow(append(row,
+			fmt.Sprintf("%s (%.1f%%)", total.Statements.Coverage, total.Statements.Percentage),
+			fmt.Sprintf("%s (%.1f%%)", total.Blocks.Coverage, total.Blocks.Percentage),
+			lines,
+		))
+	}
+
+	renderWriter(t, cfg)
+}
+
+// renderHistorySeries renders the entries as a flat time series with one row
+// per entry and scope, being the total, each package, and each file, so the
+// history can be loaded into spreadsheets. The columns are the same whatever
+// the entries hold, and coverage is split into numeric covered and total
+// columns, as for results. Build cells are empty for entries without a build,
+// and line cells for entries saved before line coverage was tracked.
+func renderHistorySeries(entries []history.Entry, cfg *config.Config) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(getTableStyle(cfg))
+	t.AppendHeader(table.Row{
+		"Timestamp", "Commit", "Branch", "Tags", "Label", "Build", "Build URL", "Scope", "Name",
+		colStatementsCovered, colStatementsTotal, colStatementPct,
+		colBlocksCovered, colBlocksTotal, colBlockPct,
+		colLinesCovered, colLinesTotal, colLinePct,
+	})
+
+	for _, entry := range entries {
+		prefix := table.Row{
+			entry.Timestamp.Format(time.RFC3339),
+			entry.Commit,
+			entry.Branch,
+			strings.Join(entry.Tags, " "),
+			entry.Label,
+			entry.Build.Summary(),
+			entry.Build.Field("url"),
+		}
+		appendRow := func(scope, name string, by compute.By) {
+			row := append(slices.Clone(prefix), scope, name)
+			row = append(row, by.StatementsCovered, by.StatementsTotal)
+			row = append(row, fmt.Sprintf("%.1f", by.StatementPercentage))
+			row = append(row, by.BlocksCovered, by.BlocksTotal)
+			row = append(row, fmt.Sprintf("%.1f", by.BlockPercentage))
+			if !entry.HasLines() {
+				row = append(row, "", "", "")
+			} else {
+				row = append(row, by.LinesCovered, by.LinesTotal)
+				row = append(row, fmt.Sprintf("%.1f", by.LinePercentage))
+			}
+			t.AppendRow(row)
+		}
+
+		total := entry.Results.ByTotal
+		appendRow("total", "total", compute.By{
+			Statements:          total.Statements.Coverage,
+			StatementsCovered:   total.Statements.Covered,
+			StatementsTotal:     total.Statements.Total,
+			StatementPercentage: total.Statements.Percentage,
+			Blocks:              total.Blocks.Coverage,
+			BlocksCovered:       total.Blocks.Covered,
+			BlocksTotal:         total.Blocks.Total,
+			BlockPercentage:     total.Blocks.Percentage,
+			Lines:               total.Lines.Coverage,
+			LinesCovered:        total.Lines.Covered,
+			LinesTotal:          total.Lines.Total,
+			LinePercentage:      total.Lines.Percentage,
+		})
+		for _, p := range entry.Results.ByPackage {
+			appendRow("package", p.Package, p.By)
+		}
+		for _, f := range entry.Results.ByFile {
+			appendRow("file", f.File, f.By)
+		}
+	}
+
+	renderWriter(t, cfg)
+}
+
+// hasBuilds reports whether any of the entries records the build that
+// produced it, which adds build columns to the history summary tables.
+func hasBuilds(entries []history.Entry) bool {
+	return slices.ContainsFunc(entries, func(entry history.Entry) bool {
+		return entry.Build != nil
+	})
+}
+
 func formatDelta(delta float64) (string, bool) {
 	if delta == 0 {
 		return "", false
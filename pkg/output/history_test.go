@@ -1,7 +1,11 @@
 package output_test
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mach6/go-covercheck/pkg/compute"
 	"github.com/mach6/go-covercheck/pkg/config"
@@ -10,6 +14,7 @@ import (
 	"github.com/mach6/go-covercheck/pkg/test"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/tools/cover"
+	"gopkg.in/yaml.v3"
 )
 
 func TestCompareHistory(t *testing.T) {
@@ -23,8 +28,11 @@ func TestCompareHistory(t *testing.T) {
 		hPath := test.CreateTempHistoryFile(t, test.TestCoverageHistory)
 		h, err := history.Load(hPath)
 		require.NoError(t, err)
-		entry := h.FindByRef("main")
-		output.CompareHistory("main", entry, results)
+		entry, err := h.FindByRef("main")
+		require.NoError(t, err)
+		cfg := new(config.Config)
+		cfg.ApplyDefaults()
+		output.ReportComparison(history.Compare("main", entry, results), cfg)
 	})
 
 	require.Empty(t, stderr)
@@ -59,3 +67,462 @@ func TestShowHistory(t *testing.T) {
 ≡ Showing last 1 history entry
 `, stdout)
 }
+
+func TestFormatAndReportWithComparison_Markdown(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatMD
+	cfg.NoSummary = true
+
+	cPath := test.CreateTempCoverageFile(t, test.TestCoverageOut)
+	profiles, err := cover.ParseProfiles(cPath)
+	require.NoError(t, err)
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		h, err := history.Load(test.CreateTempHistoryFile(t, test.TestCoverageHistory))
+		require.NoError(t, err)
+		entry, err := h.FindByRef("main")
+		require.NoError(t, err)
+		results, failed := compute.CollectResults(profiles, cfg)
+		comparison := history.Compare("main", entry, results)
+		output.FormatAndReportWithComparison(results, comparison, nil, cfg, failed)
+	})
+	require.Empty(t, stderr)
+	require.NotContains(t, stdout, "Comparing against ref")
+	require.Contains(t, stdout, "\n\n| Ref | Scope | Name | Metric | Old % | New % | Delta |\n")
+	require.Contains(t, stdout,
+		"| main | file | github.com/mach6/go-covercheck/pkg/math/math.go | statements | 75.0 | 50.0 | -25.0 |")
+	require.Contains(t, stdout, "| main | total | total | blocks | 23.2 | 50.0 | +26.8 |")
+}
+
+func TestFormatAndReportWithComparison_CSV(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatCSV
+	cfg.NoSummary = true
+
+	cPath := test.CreateTempCoverageFile(t, test.TestCoverageOut)
+	profiles, err := cover.ParseProfiles(cPath)
+	require.NoError(t, err)
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		h, err := history.Load(test.CreateTempHistoryFile(t, test.TestCoverageHistory))
+		require.NoError(t, err)
+		entry, err := h.FindByRef("main")
+		require.NoError(t, err)
+		results, failed := compute.CollectResults(profiles, cfg)
+		comparison := history.Compare("main", entry, results)
+		output.FormatAndReportWithComparison(results, comparison, nil, cfg, failed)
+	})
+	require.Empty(t, stderr)
+
+	// the comparison is omitted so the output parses as the results table alone
+	records, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	require.NotContains(t, stdout, "Ref,Scope,Name,Metric")
+}
+
+func TestFormatAndReportWithComparison_NDJSON(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatNDJSON
+
+	cPath := test.CreateTempCoverageFile(t, test.TestCoverageOut)
+	profiles, err := cover.ParseProfiles(cPath)
+	require.NoError(t, err)
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		h, err := history.Load(test.CreateTempHistoryFile(t, test.TestCoverageHistory))
+		require.NoError(t, err)
+		entry, err := h.FindByRef("main")
+		require.NoError(t, err)
+		results, failed := compute.CollectResults(profiles, cfg)
+		comparison := history.Compare("main", entry, results)
+		output.FormatAndReportWithComparison(results, comparison, nil, cfg, failed)
+	})
+	require.Empty(t, stderr)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 5)
+	require.True(t, strings.HasPrefix(lines[3], `{"type":"comparison","ref":"main",`))
+	require.True(t, strings.HasPrefix(lines[4], `{"type":"summary"`))
+}
+
+func TestCompareHistory_AddedAndRemoved(t *testing.T) {
+	entry := &history.Entry{
+		Commit: "abc1234def",
+		Results: compute.Results{
+			ByFile: []compute.ByFile{
+				{File: "old/old.go", By: compute.By{StatementPercentage: 90, BlockPercentage: 80}},
+			},
+			ByPackage: []compute.ByPackage{
+				{Package: "old", By: compute.By{StatementPercentage: 90, BlockPercentage: 80}},
+			},
+		},
+	}
+	results := compute.Results{
+		ByFile: []compute.ByFile{
+			{File: "new/good.go", By: compute.By{
+				Lines: "1/1", StatementPercentage: 100, BlockPercentage: 100, LinePercentage: 100,
+			}},
+			{File: "new/bad.go", By: compute.By{
+				Lines: "1/2", StatementPercentage: 25, BlockPercentage: 50, LinePercentage: 50,
+			}},
+		},
+		ByPackage: []compute.ByPackage{
+			{Package: "new", By: compute.By{
+				Lines: "2/3", StatementPercentage: 50, BlockPercentage: 60, LinePercentage: 66.7,
+			}},
+		},
+	}
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		c := history.Compare("main", entry, results)
+		require.True(t, c.ApplyNewFileThreshold(80))
+		output.RenderComparison(c)
+	})
+	require.Empty(t, stderr)
+	require.Equal(t, `
+≡ Comparing against ref: main [commit abc1234]
+ → New Files
+    [+] new/good.go [S 100.0%] [B 100.0%] [L 100.0%]
+    [✘] new/bad.go [S 25.0%] [B 50.0%] [L 50.0%] [below 80.0% new file threshold]
+ → Removed Files
+    [−] old/old.go [S 90.0%] [B 80.0%]
+ → New Packages
+    [+] new [S 50.0%] [B 60.0%] [L 66.7%]
+ → Removed Packages
+    [−] old [S 90.0%] [B 80.0%]
+`, stdout)
+}
+
+func TestReportComparison_Lines(t *testing.T) {
+	c := history.CompareEntries("v1", &history.Entry{Commit: "aaaaaaa1"}, "v2", &history.Entry{Commit: "bbbbbbb2"})
+	c.Lines = []history.LineChange{
+		{File: "pkg/calc/calc.go", NewlyUncovered: "6-7", NewlyCovered: "10"},
+		{File: "pkg/math/math.go", NewlyUncovered: "3"},
+	}
+
+	tests := map[string]string{
+		config.FormatTable: "\n≡ Comparing ref: v2 [commit bbbbbbb] against ref: v1 [commit aaaaaaa]\n" +
+			" → Newly Uncovered Lines\n    [−] pkg/calc/calc.go 6-7\n    [−] pkg/math/math.go 3\n" +
+			" → Newly Covered Lines\n    [+] pkg/calc/calc.go 10\n",
+		config.FormatCSV: "Ref,Scope,Name,Metric,Old %,New %,Delta\n" +
+			"v1,file,pkg/calc/calc.go,newly uncovered lines,,,6-7\n" +
+			"v1,file,pkg/calc/calc.go,newly covered lines,,,10\n" +
+			"v1,file,pkg/math/math.go,newly uncovered lines,,,3\n",
+	}
+	for format, want := range tests {
+		t.Run(format, func(t *testing.T) {
+			cfg := new(config.Config)
+			cfg.ApplyDefaults()
+			cfg.Format = format
+			stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+				output.ReportComparison(c, cfg)
+			})
+			require.Empty(t, stderr)
+			require.Equal(t, want, stdout)
+		})
+	}
+}
+
+func TestReportComparison_ShortCommits(t *testing.T) {
+	c := history.CompareEntries("v1", &history.Entry{Commit: "unknown"}, "v2", &history.Entry{Commit: "abc"})
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		output.ReportComparison(c, cfg)
+	})
+	require.Empty(t, stderr)
+	require.Equal(t, "\n≡ Comparing ref: v2 [commit abc] against ref: v1 [commit unknown]\n → No change\n", stdout)
+}
+
+func TestReportComparison(t *testing.T) {
+	from := &history.Entry{
+		Commit: "aaaaaaa1",
+		Results: compute.Results{
+			ByTotal: compute.Totals{Statements: compute.TotalStatements{Percentage: 40}},
+		},
+	}
+	to := &history.Entry{
+		Commit: "bbbbbbb2",
+		Label:  "integration",
+		Results: compute.Results{
+			ByTotal: compute.Totals{Statements: compute.TotalStatements{Percentage: 45}},
+		},
+	}
+	c := history.CompareEntries("v1", from, "v2", to)
+
+	tests := map[string]string{
+		config.FormatTable: "\n≡ Comparing ref: v2 [commit bbbbbbb@integration] against ref: v1 [commit aaaaaaa]\n" +
+			" → By Total\n    [S] total [+5.0 %]\n",
+		config.FormatCSV: "Ref,Scope,Name,Metric,Old %,New %,Delta\nv1,total,total,statements,40.0,45.0,+5.0\n",
+	}
+	for format, want := range tests {
+		t.Run(format, func(t *testing.T) {
+			cfg := new(config.Config)
+			cfg.ApplyDefaults()
+			cfg.Format = format
+			stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+				output.ReportComparison(c, cfg)
+			})
+			require.Empty(t, stderr)
+			require.Equal(t, want, stdout)
+		})
+	}
+
+	t.Run(config.FormatNDJSON, func(t *testing.T) {
+		cfg := new(config.Config)
+		cfg.ApplyDefaults()
+		cfg.Format = config.FormatNDJSON
+		stdout, _ := test.RepipeStdOutAndErrForTest(func() {
+			output.ReportComparison(c, cfg)
+		})
+		require.Equal(t, 1, strings.Count(stdout, "\n"))
+		require.True(t, strings.HasPrefix(stdout, `{"type":"comparison","ref":"v1",`))
+		require.Contains(t, stdout, `"target":{"ref":"v2","commit":"bbbbbbb2"`)
+	})
+}
+
+func TestShowHistoryTrend(t *testing.T) {
+	entry := func(day int, stmts, blocks float64, lines string, linePct float64) history.Entry {
+		return history.Entry{
+			Commit:    "e40262964cc463a18753e2834c04230c2a356f20",
+			Branch:    "main",
+			Timestamp: time.Date(2025, 7, day, 0, 0, 0, 0, time.UTC),
+			Results: compute.Results{
+				ByPackage: []compute.ByPackage{
+					{Package: "pkg/math", By: compute.By{
+						StatementPercentage: stmts, BlockPercentage: blocks, Lines: lines, LinePercentage: linePct,
+					}},
+				},
+				ByTotal: compute.Totals{
+					Statements: compute.TotalStatements{Percentage: stmts},
+					Blocks:     compute.TotalBlocks{Percentage: blocks},
+					Lines:      compute.TotalLines{Coverage: lines, Percentage: linePct},
+				},
+			},
+		}
+	}
+	h := history.New("")
+	h.Entries = []history.Entry{
+		entry(20, 62, 55, "6/10", 60),
+		entry(19, 68, 57, "6/10", 66),
+		entry(18, 71, 58, "", 0),
+		entry(17, 90, 90, "9/10", 90),
+	}
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		output.ShowHistoryTrend(h, 3, new(config.Config))
+	})
+	require.Empty(t, stderr)
+	require.Equal(t, `≡ Total coverage trend [2025-07-18 → 2025-07-20]
+    [S] █▆▁ 71.0% → 62.0% [min 62.0%, max 71.0%, slope −4.50%]
+    [B] █▆▁ 58.0% → 55.0% [min 55.0%, max 58.0%, slope −1.50%]
+    [L] █▁ 66.0% → 60.0% [min 60.0%, max 66.0%, slope −6.00%]
+
+  80.0% ┤
+        ┤
+        ┤
+        ┤S·
+        ┤  ·S
+  65.0% ┤   L··
+        ┤     ·S
+        ┤B·    L
+        ┤  ·B··B
+        ┤
+  50.0% ┤
+        └───────
+         2025-07-18 → 2025-07-20
+
+┌──────────┬─────────┬───────┬───────┬───────┬───────┬────────┐
+│  PACKAGE │  TREND  │ FIRST │  LAST │  MIN  │  MAX  │  SLOPE │
+├──────────┼─────────┼───────┼───────┼───────┼───────┼────────┤
+│ pkg/math │ █▆▁ [S] │ 71.0% │ 62.0% │ 62.0% │ 71.0% │ −4.50% │
+│          │ █▆▁ [B] │ 58.0% │ 55.0% │ 55.0% │ 58.0% │ −1.50% │
+│          │ █▁ [L]  │ 66.0% │ 60.0% │ 60.0% │ 66.0% │ −6.00% │
+└──────────┴─────────┴───────┴───────┴───────┴───────┴────────┘
+≡ Showing trend over last 3 history entries
+`, stdout)
+}
+
+func TestShowHistoryTrend_Formats(t *testing.T) {
+	entry := func(day int, stmts float64, lines string, linePct float64) history.Entry {
+		by := compute.By{StatementPercentage: stmts, BlockPercentage: stmts, Lines: lines, LinePercentage: linePct}
+		return history.Entry{
+			Commit:    "e40262964cc463a18753e2834c04230c2a356f20",
+			Timestamp: time.Date(2025, 7, day, 0, 0, 0, 0, time.UTC),
+			Results: compute.Results{
+				ByPackage: []compute.ByPackage{{Package: "pkg/math", By: by}},
+				ByTotal: compute.Totals{
+					Statements: compute.TotalStatements{Percentage: stmts},
+					Blocks:     compute.TotalBlocks{Percentage: stmts},
+					Lines:      compute.TotalLines{Coverage: lines, Percentage: linePct},
+				},
+			},
+		}
+	}
+	h := history.New("")
+	h.Entries = []history.Entry{entry(20, 60, "6/10", 60), entry(19, 70, "", 0)}
+
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatCSV
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		output.ShowHistoryTrend(h, 0, cfg)
+	})
+	require.Empty(t, stderr)
+	require.Equal(t, `Scope,Name,Metric,Points,First %,Last %,Min %,Max %,Slope
+total,total,statements,2,70.0,60.0,60.0,70.0,-10.00
+total,total,blocks,2,70.0,60.0,60.0,70.0,-10.00
+total,total,lines,1,60.0,60.0,60.0,60.0,+0.00
+package,pkg/math,statements,2,70.0,60.0,60.0,70.0,-10.00
+package,pkg/math,blocks,2,70.0,60.0,60.0,70.0,-10.00
+package,pkg/math,lines,1,60.0,60.0,60.0,60.0,+0.00
+`, stdout)
+
+	cfg.Format = config.FormatJSON
+	cfg.NoColor = true
+	stdout, stderr = test.RepipeStdOutAndErrForTest(func() {
+		output.ShowHistoryTrend(h, 0, cfg)
+	})
+	require.Empty(t, stderr)
+	var trend history.Trend
+	require.NoError(t, json.Unmarshal([]byte(stdout), &trend))
+	require.Equal(t, 2, trend.Count)
+	require.Equal(t, []float64{70, 60}, trend.ByTotal.Statements.Values)
+	require.Len(t, trend.ByPackage, 1)
+
+	cfg.Format = config.FormatNDJSON
+	stdout, _ = test.RepipeStdOutAndErrForTest(func() {
+		output.ShowHistoryTrend(h, 0, cfg)
+	})
+	require.Equal(t, 1, strings.Count(stdout, "\n"))
+	require.True(t, strings.HasPrefix(stdout, `{"type":"trend","from":"2025-07-19T00:00:00Z",`))
+}
+
+func TestShowHistoryTrend_NoEntries(t *testing.T) {
+	stdout, _ := test.RepipeStdOutAndErrForTest(func() {
+		output.ShowHistoryTrend(history.New(""), 0, new(config.Config))
+	})
+	require.Equal(t, "≡ No history entries to show\n", stdout)
+}
+
+func TestShowHistory_Formats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: config.FormatCSV,
+			want: `Timestamp,Commit,Branch,Tags,Label,Build,Build URL,Scope,Name,` +
+				`Statements Covered,Statements Total,Statement %,Blocks Covered,Blocks Total,Block %,Lines Covered,Lines Total,Line %
+2025-07-18T08:41:38Z,e40262964cc463a18753e2834c04230c2a356f20,main,,,,,total,total,180,648,27.8,95,409,23.2,,,
+2025-07-18T08:41:38Z,e40262964cc463a18753e2834c04230c2a356f20,main,,,,,package,` +
+				`github.com/mach6/go-covercheck/pkg/math,3,4,75.0,3,4,75.0,,,
+2025-07-18T08:41:38Z,e40262964cc463a18753e2834c04230c2a356f20,main,,,,,file,` +
+				`github.com/mach6/go-covercheck/pkg/math/math.go,3,4,75.0,3,4,75.0,,,
+`,
+		},
+		{
+			format: config.FormatMD,
+			want: `| Timestamp | Commit | Branch | Tags | Label | Statements | Blocks | Lines |
+|:--- |:--- |:--- |:--- |:--- | ---:| ---:| ---:|
+| 2025-07-18T08:41:38Z | e402629 | main |  |  | 180/648 (27.8%) | 95/409 (23.2%) |  |
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cfg := new(config.Config)
+			cfg.ApplyDefaults()
+			cfg.Format = tt.format
+
+			stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+				h, err := history.Load(test.CreateTempHistoryFile(t, test.TestCoverageHistory))
+				require.NoError(t, err)
+				output.ShowHistory(h, 0, cfg)
+			})
+			require.Empty(t, stderr)
+			require.Equal(t, tt.want, stdout)
+		})
+	}
+}
+
+func TestShowHistory_Build(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatMD
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		h, err := history.Load(test.CreateTempHistoryFile(t, test.TestCoverageHistory))
+		require.NoError(t, err)
+		h.Entries[0].Build = &history.Build{
+			Provider: "gitlab", Number: "9", URL: "https://gitlab.com/jobs/9", GoVersion: "go1.26.0",
+		}
+		output.ShowHistory(h, 0, cfg)
+	})
+	require.Empty(t, stderr)
+	require.Equal(t, `| Timestamp | Commit | Branch | Tags | Label | Build | Build URL | Statements | Blocks | Lines |
+|:--- |:--- |:--- |:--- |:--- |:--- |:--- | ---:| ---:| ---:|
+| 2025-07-18T08:41:38Z | e402629 | main |  |  | gitlab #9, go1.26.0 | https://gitlab.com/jobs/9 | 180/648 (27.8%) | 95/409 (23.2%) |  |
+`, stdout)
+}
+
+func TestShowHistory_StructuredFormats(t *testing.T) {
+	for _, format := range []string{config.FormatJSON, config.FormatYAML, config.FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			cfg := new(config.Config)
+			cfg.ApplyDefaults()
+			cfg.Format = format
+			cfg.NoColor = true
+
+			stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+				h, err := history.Load(test.CreateTempHistoryFile(t, test.TestCoverageHistory))
+				require.NoError(t, err)
+				output.ShowHistory(h, 0, cfg)
+			})
+			require.Empty(t, stderr)
+
+			var h history.History
+			switch format {
+			case config.FormatYAML:
+				require.NoError(t, yaml.Unmarshal([]byte(stdout), &h))
+			case config.FormatNDJSON:
+				var record output.NDJSONEntry
+				require.NoError(t, json.Unmarshal([]byte(stdout), &record))
+				require.Equal(t, output.NDJSONTypeEntry, record.Type)
+				h.Entries = append(h.Entries, record.Entry)
+			default:
+				require.NoError(t, json.Unmarshal([]byte(stdout), &h))
+			}
+			require.Len(t, h.Entries, 1)
+			require.Equal(t, "e40262964cc463a18753e2834c04230c2a356f20", h.Entries[0].Commit)
+			require.Equal(t, "180/648", h.Entries[0].Results.ByTotal.Statements.Coverage)
+		})
+	}
+}
+
+func TestShowPrunedHistory(t *testing.T) {
+	removed := []history.Entry{
+		{Commit: "abc1234def", Branch: "main", Timestamp: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{Commit: "fed4321cba", Branch: "main", Label: "nightly", Timestamp: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		output.ShowPrunedHistory(removed, true, cfg)
+	})
+	require.Empty(t, stderr)
+	require.Equal(t, `    [−] abc1234 [main 2025-07-01]
+    [−] fed4321@nightly [main 2025-06-01]
+≡ Would prune 2 history entries
+`, stdout)
+
+	stdout, _ = test.RepipeStdOutAndErrForTest(func() {
+		output.ShowPrunedHistory(removed[:1], false, cfg)
+	})
+	require.Equal(t, "    [−] abc1234 [main 2025-07-01]\n≡ Pruned 1 history entry\n", stdout)
+}
@@ -0,0 +1,120 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/history"
+)
+
+// NDJSON record types. Every ndjson line carries one of these in its "type"
+// field so consumers can dispatch on it without inspecting the other fields.
+const (
+	NDJSONTypeMetadata   = "metadata"
+	NDJSONTypeFile       = "file"
+	NDJSONTypePackage    = "package"
+	NDJSONTypeTotal      = "total"
+	NDJSONTypeComparison = "comparison"
+	NDJSONTypeSummary    = "summary"
+	NDJSONTypeEntry      = "entry"
+	NDJSONTypeTrend      = "trend"
+)
+
+// NDJSONFile is the ndjson record of a file result.
+type NDJSONFile struct {
+	Type string `json:"type"`
+	compute.ByFile
+}
+
+// NDJSONPackage is the ndjson record of a package result.
+type NDJSONPackage struct {
+	Type string `json:"type"`
+	compute.ByPackage
+}
+
+// NDJSONTotal is the ndjson record of the total results.
+type NDJSONTotal struct {
+	Type string `json:"type"`
+	compute.Totals
+}
+
+// NDJSONComparison is the ndjson record of a history comparison. It follows
+// the total record when a history ref is compared.
+type NDJSONComparison struct {
+	Type string `json:"type"`
+	*history.Comparison
+}
+
+// NDJSONEntry is the ndjson record of a history entry, written one per entry
+// by --show-history.
+type NDJSONEntry struct {
+	Type string `json:"type"`
+	history.Entry
+}
+
+// NDJSONTrend is the ndjson record of a history trend, written once by
+// --trend-history.
+type NDJSONTrend struct {
+	Type string `json:"type"`
+	*history.Trend
+}
+
+// NDJSONMetadata is the ndjson record of the run metadata. It is the first
+// record when --envelope is set.
+type NDJSONMetadata struct {
+	Type          string `json:"type"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Metadata
+}
+
+// NDJSONSummary is the last ndjson record of a run.
+type NDJSONSummary struct {
+	Type           string `json:"type"`
+	Failed         bool   `json:"failed"`
+	Files          int    `json:"files"`
+	FailedFiles    int    `json:"failedFiles"`
+	Packages       int    `json:"packages"`
+	FailedPackages int    `json:"failedPackages"`
+}
+
+// renderNDJSON writes one compact json record per line: the optional
+// metadata, each file, each package, the totals, the optional history
+// comparison, and a closing summary.
+// The records are written once every result has been collected, so consumers
+// can process them line by line but do not receive them as they are computed.
+func renderNDJSON(results compute.Results, comparison *history.Comparison, meta *Metadata, hasFailure bool) {
+	enc := json.NewEncoder(os.Stdout)
+	encode := func(v any) {
+		bailOnError(enc.Encode(v))
+	}
+
+	if meta != nil {
+		encode(NDJSONMetadata{Type: NDJSONTypeMetadata, SchemaVersion: EnvelopeSchemaVersion, Metadata: *meta})
+	}
+
+	summary := NDJSONSummary{
+		Type:     NDJSONTypeSummary,
+		Failed:   hasFailure || (comparison != nil && comparison.Failed()),
+		Files:    len(results.ByFile),
+		Packages: len(results.ByPackage),
+	}
+	for _, r := range results.ByFile {
+		if r.Failed {
+			summary.FailedFiles++
+		}
+		encode(NDJSONFile{Type: NDJSONTypeFile, ByFile: r})
+	}
+	for _, r := range results.ByPackage {
+		if r.Failed {
+			summary.FailedPackages++
+		}
+		encode(NDJSONPackage{Type: NDJSONTypePackage, ByPackage: r})
+	}
+
+	encode(NDJSONTotal{Type: NDJSONTypeTotal, Totals: results.ByTotal})
+	if comparison != nil {
+		encode(NDJSONComparison{Type: NDJSONTypeComparison, Comparison: comparison})
+	}
+	encode(summary)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/mach6/go-covercheck/pkg/compute"
 	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/history"
 	"gopkg.in/yaml.v3"
 )
 
@@ -22,7 +23,7 @@ func bailOnError(err error) {
 // PrintDiffWarning prints a warning message when git diff operations fail.
 func PrintDiffWarning(err error, cfg *config.Config) {
 	// Don't print warnings in JSON/YAML mode as they would contaminate the output
-	if cfg.Format == config.FormatJSON || cfg.Format == config.FormatYAML {
+	if cfg.IsDocumentFormat() {
 		return
 	}
 	fmt.Printf("Warning: Failed to get changed files for diff mode: %v\n", err)
@@ -32,7 +33,7 @@ func PrintDiffWarning(err error, cfg *config.Config) {
 // PrintNoDiffChanges prints a message when no files have changed in diff mode.
 func PrintNoDiffChanges(cfg *config.Config) {
 	// Don't print messages in JSON/YAML mode as they would contaminate the output
-	if cfg.Format == config.FormatJSON || cfg.Format == config.FormatYAML {
+	if cfg.IsDocumentFormat() {
 		return
 	}
 	fmt.Println("No files changed in diff. No coverage to check.")
@@ -41,7 +42,7 @@ func PrintNoDiffChanges(cfg *config.Config) {
 // PrintDiffModeInfo prints information about how many files are being checked in diff mode.
 func PrintDiffModeInfo(changedCount, totalCount int, cfg *config.Config) {
 	// Don't print info messages in JSON/YAML mode as they would contaminate the output
-	if cfg.Format == config.FormatJSON || cfg.Format == config.FormatYAML {
+	if cfg.IsDocumentFormat() {
 		return
 	}
 	fmt.Printf("Diff mode: Checking coverage for %d changed files (out of %d total files)\n",
@@ -57,6 +58,27 @@ func isEmptyResults(results compute.Results) bool {
 
 // FormatAndReport writes out formatted profile results.
 func FormatAndReport(results compute.Results, cfg *config.Config, hasFailure bool) {
+	FormatAndReportWithComparison(results, nil, nil, cfg, hasFailure)
+}
+
+// Report is the json/yaml document of a run: the results and, when a history
+// ref is compared, the comparison against it.
+type Report struct {
+	compute.Results `yaml:",inline"`
+	Comparison      *history.Comparison `json:"comparison,omitempty" yaml:"comparison,omitempty"`
+}
+
+// FormatAndReportWithComparison writes out formatted profile results followed
+// by the history comparison, when not nil, in the same format. json and yaml
+// embed the comparison in the results document, ndjson emits it as a record,
+// and md and html render it as a second table. csv and tsv omit it, so their
+// output stays a single table, and the dashboard lists only its regressions
+// and failed new files. A comparison that failed the new file threshold or
+// regression gating is reported with it. When meta is not nil, json and yaml
+// output are wrapped in an Envelope carrying it and ndjson output starts with
+// a metadata record; the other formats ignore meta.
+func FormatAndReportWithComparison(results compute.Results, comparison *history.Comparison, meta *Metadata,
+	cfg *config.Config, hasFailure bool) {
 	isEmpty := isEmptyResults(results)
 	switch cfg.Format {
 	case config.FormatTable, config.FormatMD, config.FormatHTML, config.FormatCSV, config.FormatTSV:
@@ -67,27 +89,60 @@ func FormatAndReport(results compute.Results, cfg *config.Config, hasFailure boo
 			_ = os.Stdout.Sync()
 			renderSummary(hasFailure, results, cfg)
 		}
+		if comparison != nil {
+			switch cfg.Format {
+			case config.FormatTable:
+				renderComparison(comparison)
+			case config.FormatMD, config.FormatHTML:
+				// separate from the results table so the two are not read as one.
+				fmt.Println()
+				renderComparisonTable(comparison, cfg)
+			}
+			renderRegressionSummary(comparison, cfg)
+		}
+	case config.FormatJSON, config.FormatYAML:
+		var doc any = Report{Results: results, Comparison: comparison}
+		if meta != nil {
+			doc = Envelope{
+				SchemaVersion: EnvelopeSchemaVersion,
+				Metadata:      *meta,
+				Results:       results,
+				Comparison:    comparison,
+			}
+		}
+		writeStructured(doc, cfg)
+	case config.FormatNDJSON:
+		renderNDJSON(results, comparison, meta, hasFailure)
+	case config.FormatDashboard:
+		renderDashboard(results, comparison, cfg, hasFailure)
+	default:
+		bailOnError(errors.New(color.RedString("Unsupported format: %s", cfg.Format)))
+	}
+}
+
+// writeStructured encodes v as json or yaml according to cfg.Format,
+// highlighting the output unless color is disabled.
+func writeStructured(v any, cfg *config.Config) {
+	switch cfg.Format {
 	case config.FormatJSON:
 		if cfg.NoColor {
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
-			err := enc.Encode(results)
+			err := enc.Encode(v)
 			bailOnError(err)
 		} else {
-			jsonString, err := json.MarshalIndent(results, "", "  ")
+			jsonString, err := json.MarshalIndent(v, "", "  ")
 			bailOnError(err)
 			fmt.Println(highlightJSONSyntax(string(jsonString), cfg))
 		}
 	case config.FormatYAML:
 		if cfg.NoColor {
-			err := yaml.NewEncoder(os.Stdout).Encode(results)
+			err := yaml.NewEncoder(os.Stdout).Encode(v)
 			bailOnError(err)
 		} else {
-			yamlData, err := yaml.Marshal(results)
+			yamlData, err := yaml.Marshal(v)
 			bailOnError(err)
 			fmt.Println(highlightYAMLSyntax(string(yamlData), cfg))
 		}
-	default:
-		bailOnError(errors.New(color.RedString("Unsupported format: %s", cfg.Format)))
 	}
 }
@@ -3,6 +3,7 @@ package output
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/fatih/color"
@@ -243,6 +244,201 @@ func TestFormatAndReport_EmptyResults_CSV(t *testing.T) {
 	require.Contains(t, stdout, "No coverage results to display")
 }
 
+func TestFormatAndReport_CSVOutput_NumericColumns(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatCSV
+	cfg.NoColor = true
+	cfg.NoSummary = true
+	cfg.NoUncoveredLines = true
+	color.NoColor = cfg.NoColor
+	text.DisableColors()
+
+	profiles := []*cover.Profile{
+		{
+			FileName: "main.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 3, Count: 1},
+				{NumStmt: 2, Count: 0},
+			},
+		},
+	}
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		results, failed := compute.CollectResults(profiles, cfg)
+		FormatAndReport(results, cfg, failed)
+	})
+
+	require.Empty(t, stderr)
+	require.Contains(t, stdout, ",Statements Covered,Statements Total,Blocks Covered,Blocks Total,"+
+		"Lines Covered,Lines Total,Statement %,Block %,Line %")
+	require.Contains(t, stdout, "main.go,3,5,1,2,")
+	require.NotContains(t, stdout, "3/5")
+}
+
+func TestFormatAndReportWithComparison_JSONEnvelope(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatJSON
+	cfg.NoColor = true
+	cfg.DiffFrom = "main"
+
+	profiles := []*cover.Profile{
+		{
+			FileName: "main.go",
+			Mode:     "set",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 5, Count: 1},
+			},
+		},
+	}
+
+	meta := NewMetadata(cfg, t.TempDir(), "set", "example.com/mod")
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		results, failed := compute.CollectResults(profiles, cfg)
+		FormatAndReportWithComparison(results, nil, &meta, cfg, failed)
+	})
+	require.Empty(t, stderr)
+
+	var env Envelope
+	require.NoError(t, json.Unmarshal([]byte(stdout), &env))
+	require.Equal(t, EnvelopeSchemaVersion, env.SchemaVersion)
+	require.Equal(t, config.AppName, env.Metadata.Tool)
+	require.Equal(t, config.AppVersion, env.Metadata.Version)
+	require.Equal(t, "unknown", env.Metadata.Commit)
+	require.Equal(t, "set", env.Metadata.ProfileMode)
+	require.Equal(t, "example.com/mod", env.Metadata.ModuleName)
+	require.Equal(t, "main", env.Metadata.DiffFrom)
+	require.InDelta(t, config.StatementThresholdDefault, env.Metadata.Thresholds.Statements, 0)
+	require.Len(t, env.Results.ByFile, 1)
+}
+
+func TestFormatAndReportWithComparison_TableIgnoresMetadata(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.NoColor = true
+	cfg.NoSummary = true
+
+	profiles := []*cover.Profile{
+		{
+			FileName: "main.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 5, Count: 1},
+			},
+		},
+	}
+
+	meta := NewMetadata(cfg, t.TempDir(), "set", "")
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		results, failed := compute.CollectResults(profiles, cfg)
+		FormatAndReportWithComparison(results, nil, &meta, cfg, failed)
+	})
+	require.Empty(t, stderr)
+	require.Contains(t, stdout, "main.go")
+	require.NotContains(t, stdout, "schemaVersion")
+}
+
+func TestFormatAndReport_NDJSON(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatNDJSON
+
+	profiles := []*cover.Profile{
+		{
+			FileName: "pkg/a/a.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 5, Count: 1},
+			},
+		},
+		{
+			FileName: "pkg/b/b.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 1, Count: 1},
+				{NumStmt: 9, Count: 0},
+			},
+		},
+	}
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		results, failed := compute.CollectResults(profiles, cfg)
+		require.True(t, failed)
+		FormatAndReport(results, cfg, failed)
+	})
+	require.Empty(t, stderr)
+
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	require.Len(t, lines, 6)
+
+	types := make([]string, 0, len(lines))
+	for _, line := range lines {
+		var rec struct {
+			Type string `json:"type"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &rec), line)
+		types = append(types, rec.Type)
+	}
+	require.Equal(t, []string{
+		NDJSONTypeFile, NDJSONTypeFile,
+		NDJSONTypePackage, NDJSONTypePackage,
+		NDJSONTypeTotal, NDJSONTypeSummary,
+	}, types)
+
+	var file NDJSONFile
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &file))
+	require.Equal(t, "pkg/b/b.go", file.File)
+	require.Equal(t, 10, file.StatementsTotal)
+	require.True(t, file.Failed)
+
+	var total NDJSONTotal
+	require.NoError(t, json.Unmarshal([]byte(lines[4]), &total))
+	require.Equal(t, 6, total.Statements.Covered)
+	require.Equal(t, 15, total.Statements.Total)
+
+	var summary NDJSONSummary
+	require.NoError(t, json.Unmarshal([]byte(lines[5]), &summary))
+	require.Equal(t, NDJSONSummary{
+		Type:           NDJSONTypeSummary,
+		Failed:         true,
+		Files:          2,
+		FailedFiles:    1,
+		Packages:       2,
+		FailedPackages: 1,
+	}, summary)
+}
+
+func TestFormatAndReportWithComparison_NDJSONMetadataFirst(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.Format = config.FormatNDJSON
+
+	profiles := []*cover.Profile{
+		{
+			FileName: "main.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 5, Count: 1},
+			},
+		},
+	}
+
+	meta := NewMetadata(cfg, t.TempDir(), "set", "example.com/mod")
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		results, failed := compute.CollectResults(profiles, cfg)
+		FormatAndReportWithComparison(results, nil, &meta, cfg, failed)
+	})
+	require.Empty(t, stderr)
+
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	require.Len(t, lines, 5)
+
+	var rec NDJSONMetadata
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	require.Equal(t, NDJSONTypeMetadata, rec.Type)
+	require.Equal(t, EnvelopeSchemaVersion, rec.SchemaVersion)
+	require.Equal(t, "example.com/mod", rec.ModuleName)
+	require.Contains(t, lines[4], `"type":"summary"`)
+	require.Contains(t, lines[4], `"failed":false`)
+}
+
 func TestFormatAndReport_EmptyResults_JSON(t *testing.T) {
 	cfg := new(config.Config)
 	cfg.ApplyDefaults()
@@ -2,10 +2,12 @@ package output
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/mach6/go-covercheck/pkg/compute"
 	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/history"
 )
 
 var (
@@ -135,3 +137,61 @@ func renderBy[T compute.HasBy](by T, item string) {
 		)
 	}
 }
+
+var (
+	regressionMsgF = "    [%s] %s [%s dropped more than %s tolerance]\n"
+)
+
+// renderRegressionSummary explains each coverage regression found by the
+// history comparison, grouped by scope like renderSummary.
+func renderRegressionSummary(c *history.Comparison, cfg *config.Config) {
+	if cfg.NoSummary || len(c.Regressions) == 0 {
+		return
+	}
+
+	if c.BestOf > 0 {
+		_, _ = fmt.Println(color.New(color.FgRed).Sprint("✘"), "Coverage fell below the",
+			color.New(color.FgBlue).Sprint(c.Ref), "of", c.BestOf, "history entries")
+	} else {
+		_, _ = fmt.Println(color.New(color.FgRed).Sprint("✘"), "Coverage regressed against ref:",
+			color.New(color.FgBlue).Sprint(c.Ref))
+	}
+	headings := map[string]string{
+		config.RegressionScopeFile:    " → By File",
+		config.RegressionScopePackage: " → By Package",
+		config.RegressionScopeTotal:   " → By Total",
+	}
+	for _, scope := range []string{
+		config.RegressionScopeFile, config.RegressionScopePackage, config.RegressionScopeTotal,
+	} {
+		bPrinted := false
+		for _, r := range c.Regressions {
+			if r.Scope != scope {
+				continue
+			}
+			if !bPrinted {
+				_, _ = fmt.Println(headings[scope])
+				bPrinted = true
+			}
+			metric := metricColor(r.Metric)
+			_, _ = fmt.Printf(regressionMsgF,
+				metric.Sprint(strings.ToUpper(r.Metric[:1])),
+				r.Name,
+				color.New(color.FgRed).Sprintf("−%.1f%%", -r.Delta.Delta),
+				metric.Sprintf("%.1f%%", r.Tolerance),
+			)
+		}
+	}
+}
+
+// metricColor returns the color used for the S, B, and L markers.
+func metricColor(metric string) *color.Color {
+	switch metric {
+	case config.StatementsSection:
+		return color.New(color.FgCyan)
+	case config.BlocksSection:
+		return color.New(color.FgHiMagenta)
+	default:
+		return color.New(color.FgYellow)
+	}
+}
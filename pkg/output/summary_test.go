@@ -6,6 +6,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/mach6/go-covercheck/pkg/compute"
 	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/history"
 	"github.com/mach6/go-covercheck/pkg/output"
 	"github.com/mach6/go-covercheck/pkg/test"
 	"github.com/stretchr/testify/require"
@@ -230,3 +231,80 @@ func TestRenderSummary(t *testing.T) {
 		require.NotContains(t, stdout, "→ By Total")
 	})
 }
+
+func TestFormatAndReportWithComparison_RegressionSummary(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.NoTable = true
+	cfg.StatementThreshold = 0
+	cfg.BlockThreshold = 0
+	cfg.LineThreshold = 0
+	cfg.Total = config.PerOverride{}
+
+	c := &history.Comparison{
+		Ref:    "main",
+		Commit: "abc1234def",
+		Regressions: []history.Regression{
+			{
+				Scope: config.RegressionScopeTotal, Name: "total", Metric: config.LinesSection,
+				Delta: history.Delta{Old: 80, New: 79, Delta: -1}, Tolerance: 0.5,
+			},
+			{
+				Scope: config.RegressionScopeFile, Name: "a.go", Metric: config.StatementsSection,
+				Delta: history.Delta{Old: 80, New: 70, Delta: -10},
+			},
+		},
+	}
+	results := compute.Results{ByFile: []compute.ByFile{{File: "a.go"}}}
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		output.FormatAndReportWithComparison(results, c, nil, cfg, false)
+	})
+	require.Empty(t, stderr)
+	require.Contains(t, stdout, `✘ Coverage regressed against ref: main
+ → By File
+    [S] a.go [−10.0% dropped more than 0.0% tolerance]
+ → By Total
+    [L] total [−1.0% dropped more than 0.5% tolerance]
+`)
+
+	cfg.NoSummary = true
+	stdout, _ = test.RepipeStdOutAndErrForTest(func() {
+		output.FormatAndReportWithComparison(results, c, nil, cfg, false)
+	})
+	require.NotContains(t, stdout, "Coverage regressed")
+}
+
+func TestFormatAndReportWithComparison_BestSummary(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.NoTable = true
+	cfg.StatementThreshold = 0
+	cfg.BlockThreshold = 0
+	cfg.LineThreshold = 0
+	cfg.Total = config.PerOverride{}
+
+	c := &history.Comparison{
+		Ref:    history.BestRef,
+		BestOf: 3,
+		ByTotal: history.Deltas{
+			Statements: history.Delta{Old: 90, New: 85, Delta: -5},
+		},
+		Regressions: []history.Regression{
+			{
+				Scope: config.RegressionScopeTotal, Name: "total", Metric: config.StatementsSection,
+				Delta: history.Delta{Old: 90, New: 85, Delta: -5},
+			},
+		},
+	}
+
+	stdout, stderr := test.RepipeStdOutAndErrForTest(func() {
+		output.FormatAndReportWithComparison(compute.Results{}, c, nil, cfg, false)
+	})
+	require.Empty(t, stderr)
+	require.Contains(t, stdout, "≡ Comparing against the best of 3 history entries\n → By Total\n")
+	require.Contains(t, stdout, `✘ Coverage fell below the best of 3 history entries
+ → By Total
+    [S] total [−5.0% dropped more than 0.0% tolerance]
+`)
+}
@@ -111,8 +111,58 @@ const (
 	colBlockPct       = "Block %"
 	colLinePct        = "Line %"
 	colUncoveredLines = "Uncovered Lines"
+
+	colStatementsCovered = "Statements Covered"
+	colStatementsTotal   = "Statements Total"
+	colBlocksCovered     = "Blocks Covered"
+	colBlocksTotal       = "Blocks Total"
+	colLinesCovered      = "Lines Covered"
+	colLinesTotal        = "Lines Total"
 )
 
+// splitCoverageColumns reports whether the "covered/total" cells should be
+// emitted as separate numeric columns. Only csv/tsv do this, so spreadsheets
+// and SQL imports receive plain integers instead of a fraction string.
+func splitCoverageColumns(cfg *config.Config) bool {
+	return cfg.Format == config.FormatCSV || cfg.Format == config.FormatTSV
+}
+
+// coverageColumnNames returns the header labels for the coverage count columns.
+func coverageColumnNames(split bool) []string {
+	if split {
+		return []string{
+			colStatementsCovered, colStatementsTotal,
+			colBlocksCovered, colBlocksTotal,
+			colLinesCovered, colLinesTotal,
+		}
+	}
+	return []string{colStatements, colBlocks, colLines}
+}
+
+// coverageCells returns the coverage count cells for a file or package row.
+func coverageCells(by compute.By, split bool) table.Row {
+	if split {
+		return table.Row{
+			by.StatementsCovered, by.StatementsTotal,
+			by.BlocksCovered, by.BlocksTotal,
+			by.LinesCovered, by.LinesTotal,
+		}
+	}
+	return table.Row{by.Statements, by.Blocks, by.Lines}
+}
+
+// totalCoverageCells returns the coverage count cells for the totals row.
+func totalCoverageCells(totals compute.Totals, split bool) table.Row {
+	if split {
+		return table.Row{
+			totals.Statements.Covered, totals.Statements.Total,
+			totals.Blocks.Covered, totals.Blocks.Total,
+			totals.Lines.Covered, totals.Lines.Total,
+		}
+	}
+	return table.Row{totals.Statements.Coverage, totals.Blocks.Coverage, totals.Lines.Coverage}
+}
+
 // applyTableWidths fills in WidthMax / WidthMaxEnforcer on the supplied column
 // configs for human-facing table output. The leading name column gets a wider
 // cap and the numeric columns are bounded to fixedColumnWidth. The trailing
@@ -248,15 +298,16 @@ func renderTable(results compute.Results, cfg *config.Config) {
 	t.SetAllowedRowLength(cfg.TerminalWidth)
 	t.SetStyle(getTableStyle(cfg))
 
-	headers := table.Row{"", "Statements", "Blocks", "Lines", "Statement %", "Block %", "Line %"}
+	split := splitCoverageColumns(cfg)
+	headers := table.Row{colName}
 	columnConfigs := []table.ColumnConfig{
-		{Name: "", Align: text.AlignLeft, AlignFooter: text.AlignLeft},
-		{Name: "Statements", Align: text.AlignRight, AlignHeader: text.AlignLeft, AlignFooter: text.AlignRight},
-		{Name: "Blocks", Align: text.AlignRight, AlignHeader: text.AlignLeft, AlignFooter: text.AlignRight},
-		{Name: "Lines", Align: text.AlignRight, AlignHeader: text.AlignLeft, AlignFooter: text.AlignRight},
-		{Name: "Statement %", Align: text.AlignRight, AlignHeader: text.AlignLeft, AlignFooter: text.AlignRight},
-		{Name: "Block %", Align: text.AlignRight, AlignHeader: text.AlignLeft, AlignFooter: text.AlignRight},
-		{Name: "Line %", Align: text.AlignRight, AlignHeader: text.AlignLeft, AlignFooter: text.AlignRight},
+		{Name: colName, Align: text.AlignLeft, AlignFooter: text.AlignLeft},
+	}
+	for _, name := range append(coverageColumnNames(split), colStatementPct, colBlockPct, colLinePct) {
+		headers = append(headers, name)
+		columnConfigs = append(columnConfigs, table.ColumnConfig{
+			Name: name, Align: text.AlignRight, AlignHeader: text.AlignLeft, AlignFooter: text.AlignRight,
+		})
 	}
 	if !cfg.NoUncoveredLines {
 		headers = append(headers, "Uncovered Lines")
@@ -286,15 +337,13 @@ func renderTable(results compute.Results, cfg *config.Config) {
 		blockColor := severityColor(r.BlockPercentage, r.BlockThreshold)
 		lineColor := severityColor(r.LinePercentage, r.LineThreshold)
 
-		row := table.Row{
-			r.File,
-			r.Statements,
-			r.Blocks,
-			r.Lines,
+		row := table.Row{r.File}
+		row = append(row, coverageCells(r.By, split)...)
+		row = append(row,
 			stmtColor(fmt.Sprintf("%.1f", r.StatementPercentage)),
 			blockColor(fmt.Sprintf("%.1f", r.BlockPercentage)),
 			lineColor(fmt.Sprintf("%.1f", r.LinePercentage)),
-		}
+		)
 
 		if !cfg.NoUncoveredLines {
 			row = append(row, r.UncoveredLines)
@@ -311,15 +360,13 @@ func renderTable(results compute.Results, cfg *config.Config) {
 		blockColor := severityColor(r.BlockPercentage, r.BlockThreshold)
 		lineColor := severityColor(r.LinePercentage, r.LineThreshold)
 
-		row := table.Row{
-			r.Package,
-			r.Statements,
-			r.Blocks,
-			r.Lines,
+		row := table.Row{r.Package}
+		row = append(row, coverageCells(r.By, split)...)
+		row = append(row,
 			stmtColor(fmt.Sprintf("%.1f", r.StatementPercentage)),
 			blockColor(fmt.Sprintf("%.1f", r.BlockPercentage)),
 			lineColor(fmt.Sprintf("%.1f", r.LinePercentage)),
-		}
+		)
 		if !cfg.NoUncoveredLines {
 			// Packages don't have uncovered lines, so show empty string
 			row = append(row, "")
@@ -335,20 +382,25 @@ func renderTable(results compute.Results, cfg *config.Config) {
 	t.AppendRow(table.Row{text.Bold.Sprint("BY TOTAL")})
 	t.AppendSeparator()
 
-	footer := table.Row{
-		"",
-		text.Bold.Sprint(results.ByTotal.Statements.Coverage),
-		text.Bold.Sprint(results.ByTotal.Blocks.Coverage),
-		text.Bold.Sprint(results.ByTotal.Lines.Coverage),
+	footer := table.Row{""}
+	for _, cell := range totalCoverageCells(results.ByTotal, split) {
+		footer = append(footer, text.Bold.Sprint(cell))
+	}
+	footer = append(footer,
 		stmtColor(text.Bold.Sprintf("%.1f", results.ByTotal.Statements.Percentage)),
 		blockColor(text.Bold.Sprintf("%.1f", results.ByTotal.Blocks.Percentage)),
 		lineColor(text.Bold.Sprintf("%.1f", results.ByTotal.Lines.Percentage)),
-	}
+	)
 	if !cfg.NoUncoveredLines {
 		footer = append(footer, "")
 	}
 	t.AppendFooter(footer)
 
+	renderWriter(t, cfg)
+}
+
+// renderWriter renders t in the tabular format selected by cfg.
+func renderWriter(t table.Writer, cfg *config.Config) {
 	switch cfg.Format {
 	case config.FormatMD:
 		t.RenderMarkdown()
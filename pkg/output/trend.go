@@ -0,0 +1,326 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/history"
+)
+
+const (
+	// trendChartHeight is the number of rows in the trend chart.
+	trendChartHeight = 10
+	// trendChartMaxWide is the most entries drawn with interpolated
+	// connectors between points; wider windows get a single column per entry.
+	trendChartMaxWide = 24
+	// trendChartStep is the percentage points the chart axis is rounded to.
+	trendChartStep = 10
+)
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// trendMetric pairs a Series with its marker and color for rendering.
+type trendMetric struct {
+	marker string
+	color  color.Attribute
+	series *history.Series
+}
+
+func trendMetrics(m history.MetricTrend) []trendMetric {
+	var metrics []trendMetric
+	for _, tm := range []trendMetric{
+		{"S", color.FgCyan, m.Statements},
+		{"B", color.FgHiMagenta, m.Blocks},
+		{"L", color.FgYellow, m.Lines},
+	} {
+		if tm.series != nil {
+			metrics = append(metrics, tm)
+		}
+	}
+	return metrics
+}
+
+// chartCell is a single character of the trend chart. Points take precedence
+// over the connectors between them, and overlapping points are drawn as "*".
+type chartCell struct {
+	mark  string
+	color color.Attribute
+	point bool
+}
+
+func (c chartCell) String() string {
+	if c.mark == "" {
+		return " "
+	}
+	return color.New(c.color).Sprint(c.mark)
+}
+
+// ShowHistoryTrend displays the coverage trend of the history entries in the
+// selected format. The table format shows sparklines and a chart of the total
+// coverage, followed by a per-package trend table, json and yaml the trend as
+// a document, ndjson a single trend record, and md, html, csv, and tsv a table
+// with one row per scope and metric.
+func ShowHistoryTrend(h *history.History, limit int, cfg *config.Config) {
+	count := limit
+	if count <= 0 || count > len(h.Entries) {
+		count = len(h.Entries)
+	}
+	trend := history.NewTrend(h.Entries[:count])
+
+	switch cfg.Format {
+	case config.FormatJSON, config.FormatYAML:
+		writeStructured(trend, cfg)
+	case config.FormatNDJSON:
+		bailOnError(json.NewEncoder(os.Stdout).Encode(NDJSONTrend{Type: NDJSONTypeTrend, Trend: trend}))
+	case config.FormatMD, config.FormatHTML, config.FormatCSV, config.FormatTSV:
+		renderTrendSeries(trend, cfg)
+	case config.FormatDashboard:
+		bailOnError(errors.New(color.RedString("Unsupported format for history trend: %s", cfg.Format)))
+	default:
+		renderTrend(trend, cfg)
+	}
+}
+
+// renderTrend displays the trend as sparklines and a chart of the total
+// coverage, followed by a per-package trend table.
+func renderTrend(trend *history.Trend, cfg *config.Config) {
+	count := trend.Count
+	if count == 0 {
+		fmt.Printf("≡ No history entries to show\n")
+		return
+	}
+	metrics := trendMetrics(trend.ByTotal)
+
+	window := trend.From.Format("2006-01-02")
+	if trend.Count > 1 {
+		window += " → " + trend.To.Format("2006-01-02")
+	}
+	fmt.Printf("≡ Total coverage trend [%s]\n", window)
+	for _, m := range metrics {
+		fmt.Printf("    [%s] %s %s\n", color.New(m.color).Sprint(m.marker), sparkline(m.series.Values),
+			formatSeries(m.series))
+	}
+
+	fmt.Println()
+	renderTrendChart(trend, metrics)
+
+	fmt.Println()
+	renderPackageTrend(trend, cfg)
+
+	fmt.Printf("≡ Showing trend over last %d history entr%s\n", count,
+		map[bool]string{true: "y", false: "ies"}[count == 1])
+}
+
+// renderTrendSeries renders the trend as a md, html, csv, or tsv table with one
+// row per metric of the total and of each package, holding the number of
+// points and the first, last, min, and max coverage and the slope of its
+// Series.
+func renderTrendSeries(trend *history.Trend, cfg *config.Config) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(getTableStyle(cfg))
+	t.AppendHeader(table.Row{"Scope", "Name", "Metric", "Points", "First %", "Last %", "Min %", "Max %", "Slope"})
+
+	appendRows := func(scope, name string, m history.MetricTrend) {
+		for _, metric := range []struct {
+			name   string
+			series *history.Series
+		}{
+			{"statements", m.Statements},
+			{"blocks", m.Blocks},
+			{"lines", m.Lines},
+		} {
+			if metric.series == nil {
+				continue
+			}
+			t.AppendRow(table.Row{
+				scope, name, metric.name, len(metric.series.Values),
+				fmt.Sprintf("%.1f", metric.series.First),
+				fmt.Sprintf("%.1f", metric.series.Last),
+				fmt.Sprintf("%.1f", metric.series.Min),
+				fmt.Sprintf("%.1f", metric.series.Max),
+				fmt.Sprintf("%+.2f", metric.series.Slope),
+			})
+		}
+	}
+	appendRows("total", "total", trend.ByTotal)
+	for _, p := range trend.ByPackage {
+		appendRows("package", p.Package, p.MetricTrend)
+	}
+
+	renderWriter(t, cfg)
+}
+
+// sparkline renders values as unicode bars scaled between their min and max.
+func sparkline(values []float64) string {
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := len(sparkBars) / 2 //nolint:mnd
+		if hi > lo {
+			idx = int(math.Round((v - lo) / (hi - lo) * float64(len(sparkBars)-1)))
+		}
+		sb.WriteRune(sparkBars[idx])
+	}
+	return sb.String()
+}
+
+func formatSeries(s *history.Series) string {
+	return fmt.Sprintf("%.1f%% → %.1f%% [min %.1f%%, max %.1f%%, slope %s]",
+		s.First, s.Last, s.Min, s.Max, formatSlope(s.Slope))
+}
+
+// formatSlope formats a slope in percentage points per entry, colored by
+// direction.
+func formatSlope(slope float64) string {
+	switch {
+	case slope < 0:
+		return color.New(color.FgRed).Sprintf("−%.2f%%", -slope)
+	case slope > 0:
+		return color.New(color.FgGreen).Sprintf("+%.2f%%", slope)
+	default:
+		return "0.00%"
+	}
+}
+
+// renderTrendChart plots the total coverage of each metric over the entries
+// as an ASCII line chart, oldest entry on the left.
+func renderTrendChart(trend *history.Trend, metrics []trendMetric) {
+	lo, hi := 100.0, 0.0
+	for _, m := range metrics {
+		lo, hi = math.Min(lo, m.series.Min), math.Max(hi, m.series.Max)
+	}
+	lo = math.Floor(lo/trendChartStep) * trendChartStep
+	hi = math.Ceil(hi/trendChartStep) * trendChartStep
+	if hi == lo {
+		hi = math.Min(lo+trendChartStep, 100) //nolint:mnd
+		lo = hi - trendChartStep
+	}
+
+	width := 1
+	if trend.Count <= trendChartMaxWide {
+		width = 3
+	}
+	cols := (trend.Count-1)*width + 1
+	grid := make([][]chartCell, trendChartHeight+1)
+	for r := range grid {
+		grid[r] = make([]chartCell, cols)
+	}
+
+	row := func(v float64) int {
+		return int(math.Round((hi - v) / (hi - lo) * trendChartHeight))
+	}
+	for _, m := range metrics {
+		values := chartValues(trend, m)
+		for i, v := range values {
+			if math.IsNaN(v) {
+				continue
+			}
+			// connect to the next point with interpolated dots.
+			if i+1 < len(values) && !math.IsNaN(values[i+1]) {
+				for c := 1; c < width; c++ {
+					iv := v + (values[i+1]-v)*float64(c)/float64(width)
+					if cell := &grid[row(iv)][i*width+c]; cell.mark == "" {
+						*cell = chartCell{mark: "·", color: m.color}
+					}
+				}
+			}
+			cell := &grid[row(v)][i*width]
+			if cell.point {
+				*cell = chartCell{mark: "*", color: color.Reset, point: true}
+				continue
+			}
+			*cell = chartCell{mark: m.marker, color: m.color, point: true}
+		}
+	}
+
+	for r, cells := range grid {
+		label := "      "
+		if r%(trendChartHeight/2) == 0 { //nolint:mnd
+			label = fmt.Sprintf("%5.1f%%", hi-float64(r)*(hi-lo)/trendChartHeight)
+		}
+		var sb strings.Builder
+		for _, c := range cells {
+			sb.WriteString(c.String())
+		}
+		fmt.Printf(" %s ┤%s\n", label, strings.TrimRight(sb.String(), " "))
+	}
+	fmt.Printf("        └%s\n", strings.Repeat("─", cols))
+	from, to := trend.From.Format("2006-01-02"), trend.To.Format("2006-01-02")
+	switch {
+	case trend.Count == 1:
+		fmt.Printf("         %s\n", from)
+	case cols < 2*len(from)+1:
+		fmt.Printf("         %s → %s\n", from, to)
+	default:
+		fmt.Printf("         %s%s%s\n", from, strings.Repeat(" ", cols-2*len(from)), to)
+	}
+}
+
+// chartValues aligns the series of m with the entries of trend, using NaN for
+// entries without data. Only lines can be missing, for entries recorded before
+// line coverage was tracked.
+func chartValues(trend *history.Trend, m trendMetric) []float64 {
+	if len(m.series.Values) == trend.Count {
+		return m.series.Values
+	}
+	values := make([]float64, 0, trend.Count)
+	for _, e := range trend.Entries {
+		if !e.HasLines() {
+			values = append(values, math.NaN())
+			continue
+		}
+		values = append(values, e.Results.ByTotal.Lines.Percentage)
+	}
+	return values
+}
+
+// renderPackageTrend displays the first, last, min, max, and slope of the
+// coverage of each package over the trend window.
+func renderPackageTrend(trend *history.Trend, cfg *config.Config) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(getHistoryTableStyle(cfg))
+
+	t.AppendHeader(table.Row{"Package", "Trend", "First", "Last", "Min", "Max", "Slope"})
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Package", Align: text.AlignLeft},
+		{Name: "Trend", Align: text.AlignLeft},
+	})
+
+	for _, p := range trend.ByPackage {
+		var spark, first, last, lo, hi, slope []string
+		for _, m := range trendMetrics(p.MetricTrend) {
+			marker := " [" + color.New(m.color).Sprint(m.marker) + "]"
+			spark = append(spark, sparkline(m.series.Values)+marker)
+			first = append(first, fmt.Sprintf("%.1f%%", m.series.First))
+			last = append(last, fmt.Sprintf("%.1f%%", m.series.Last))
+			lo = append(lo, fmt.Sprintf("%.1f%%", m.series.Min))
+			hi = append(hi, fmt.Sprintf("%.1f%%", m.series.Max))
+			slope = append(slope, formatSlope(m.series.Slope))
+		}
+		t.AppendRow(table.Row{
+			p.Package,
+			strings.Join(spark, "\n"),
+			strings.Join(first, "\n"),
+			strings.Join(last, "\n"),
+			strings.Join(lo, "\n"),
+			strings.Join(hi, "\n"),
+			strings.Join(slope, "\n"),
+		})
+	}
+
+	t.Render()
+}
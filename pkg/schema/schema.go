@@ -0,0 +1,243 @@
+// Package schema generates JSON Schemas for the go-covercheck config file and
+// structured outputs directly from the Go types, so editors and linters can
+// validate them without hand-maintained copies.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/history"
+	"github.com/mach6/go-covercheck/pkg/output"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// BaseURL is where the published schemas are served from.
+const BaseURL = "https://raw.githubusercontent.com/mach6/go-covercheck/main/schemas/"
+
+// Schema names accepted by ByName.
+const (
+	NameConfig   = "config"
+	NameResults  = "results"
+	NameEnvelope = "envelope"
+	NameHistory  = "history"
+)
+
+// Names lists all the available schemas in display order.
+var Names = []string{NameConfig, NameResults, NameEnvelope, NameHistory}
+
+// Schema is a subset of a JSON Schema document sufficient to describe the
+// go-covercheck types.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+}
+
+// options control how Go types are mapped to a Schema.
+type options struct {
+	// tagKey is the struct tag ("json" or "yaml") that names properties.
+	tagKey string
+	// required marks every field without omitempty as required.
+	required bool
+}
+
+// ByName returns the named schema or an error if the name is unknown.
+func ByName(name string) (*Schema, error) {
+	switch name {
+	case NameConfig:
+		return Config(), nil
+	case NameResults:
+		return Results(), nil
+	case NameEnvelope:
+		return Envelope(), nil
+	case NameHistory:
+		return History(), nil
+	default:
+		return nil, fmt.Errorf("unknown schema %q; must be one of %s", name, strings.Join(Names, "|"))
+	}
+}
+
+// Marshal encodes a Schema as indented JSON terminated by a newline.
+func Marshal(s *Schema) ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Config returns the schema for the .go-covercheck.yml config file.
+func Config() *Schema {
+	s := document(NameConfig, config.AppName+" config file",
+		reflect.TypeFor[config.Config](), options{tagKey: "yaml"})
+
+	percent := func(p *Schema) {
+		p.Minimum = ptr(float64(config.StatementThresholdOff))
+		p.Maximum = ptr(float64(config.StatementThresholdMax))
+	}
+	for _, name := range []string{"statementThreshold", "blockThreshold", "lineThreshold", "newFileThreshold"} {
+		percent(s.Properties[name])
+	}
+	for _, name := range []string{"perFile", "perPackage"} {
+		for _, section := range s.Properties[name].Properties {
+			percent(section.AdditionalProperties.(*Schema)) //nolint:forcetypeassert // maps always set a *Schema
+		}
+	}
+	percent(s.Properties["total"].AdditionalProperties.(*Schema)) //nolint:forcetypeassert // see above
+	for _, scope := range s.Properties["regression"].Properties["tolerance"].Properties {
+		percent(scope.AdditionalProperties.(*Schema)) //nolint:forcetypeassert // see above
+	}
+
+	s.Properties["sortBy"].Enum = []string{
+		config.SortByFile, config.SortByStatements, config.SortByBlocks, config.SortByLines,
+		config.SortByStatementPercent, config.SortByBlockPercent, config.SortByLinePercent,
+	}
+	s.Properties["sortOrder"].Enum = []string{config.SortOrderAsc, config.SortOrderDesc}
+	s.Properties["format"].Enum = []string{
+		config.FormatTable, config.FormatJSON, config.FormatYAML, config.FormatMD,
+		config.FormatHTML, config.FormatCSV, config.FormatTSV, config.FormatNDJSON, config.FormatDashboard,
+	}
+	s.Properties["tableStyle"].Enum = []string{
+		config.TableStyleDefault, config.TableStyleLight, config.TableStyleBold,
+		config.TableStyleRounded, config.TableStyleDouble,
+	}
+	s.Properties["inspectContext"].Minimum = ptr(0.0)
+	s.Properties["retention"].Properties["perBranch"].Minimum = ptr(0.0)
+	s.Properties["retention"].Properties["thin"].Enum = []string{config.ThinDay, config.ThinWeek}
+	return s
+}
+
+// Results returns the schema for json/yaml results output, including the
+// history comparison embedded with --compare-history.
+func Results() *Schema {
+	return document(NameResults, config.AppName+" results",
+		reflect.TypeFor[output.Report](), options{tagKey: "json", required: true})
+}
+
+// Envelope returns the schema for json/yaml results output wrapped with
+// --envelope.
+func Envelope() *Schema {
+	return document(NameEnvelope, config.AppName+" results envelope",
+		reflect.TypeFor[output.Envelope](), options{tagKey: "json", required: true})
+}
+
+// History returns the schema for the history file. Fields are not required
+// because files written by older versions lack the newer fields.
+func History() *Schema {
+	return document(NameHistory, config.AppName+" history file",
+		reflect.TypeFor[history.History](), options{tagKey: "json"})
+}
+
+func document(name, title string, t reflect.Type, opts options) *Schema {
+	s := generate(t, opts)
+	s.Schema = Draft
+	s.ID = BaseURL + name + ".schema.json"
+	s.Title = title
+	return s
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func generate(t reflect.Type, opts options) *Schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() { //nolint:exhaustive // unsupported kinds map to an unconstrained schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: generate(t.Elem(), opts)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: generate(t.Elem(), opts)}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+		addFields(s, t, opts)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// addFields adds the exported fields of struct t to s, flattening embedded
+// and inline structs the way encoding/json and yaml.v3 do.
+func addFields(s *Schema, t reflect.Type, opts options) {
+	for f := range t.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		name, omitEmpty, inline, skip := parseTag(f, opts.tagKey)
+		if skip {
+			continue
+		}
+		if inline {
+			addFields(s, f.Type, opts)
+			continue
+		}
+		s.Properties[name] = generate(f.Type, opts)
+		if opts.required && !omitEmpty {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// parseTag resolves a struct field's property name from its tag.
+func parseTag(f reflect.StructField, tagKey string) (string, bool, bool, bool) {
+	tag := f.Tag.Get(tagKey)
+	if tag == "-" {
+		return "", false, false, true
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	omitEmpty, inline := false, false
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			omitEmpty = true
+		case "inline":
+			inline = true
+		}
+	}
+	if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+		inline = true
+	}
+	if name == "" {
+		// yaml.v3 lowercases untagged field names; encoding/json keeps them as-is.
+		name = f.Name
+		if tagKey == "yaml" {
+			name = strings.ToLower(f.Name)
+		}
+	}
+	return name, omitEmpty, inline, false
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,142 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mach6/go-covercheck/pkg/compute"
+	"github.com/mach6/go-covercheck/pkg/config"
+	"github.com/mach6/go-covercheck/pkg/schema"
+	"github.com/mach6/go-covercheck/schemas"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/cover"
+	"gopkg.in/yaml.v3"
+)
+
+// TestPublishedSchemasInSync fails when a Go type changes without the files
+// under schemas/ being regenerated with `make schemas`.
+func TestPublishedSchemasInSync(t *testing.T) {
+	for _, name := range schema.Names {
+		t.Run(name, func(t *testing.T) {
+			s, err := schema.ByName(name)
+			require.NoError(t, err)
+			generated, err := schema.Marshal(s)
+			require.NoError(t, err)
+
+			published, err := schemas.Files.ReadFile(name + ".schema.json")
+			require.NoError(t, err, "missing schemas/%s.schema.json; run `make schemas`", name)
+			require.Equal(t, string(published), string(generated),
+				"schemas/%s.schema.json is out of date; run `make schemas`", name)
+		})
+	}
+}
+
+func TestByName_Unknown(t *testing.T) {
+	_, err := schema.ByName("bogus")
+	require.ErrorContains(t, err, `unknown schema "bogus"`)
+}
+
+func TestConfig_CoversYAMLFields(t *testing.T) {
+	cfg := new(config.Config)
+	cfg.ApplyDefaults()
+	cfg.ModuleName = "example.com/mod"
+	cfg.DiffFrom = "main"
+	cfg.NoTable = true
+	cfg.NoSummary = true
+	cfg.NoColor = true
+	cfg.NoUncoveredLines = true
+	cfg.Envelope = true
+	cfg.NewFileThreshold = 80
+	cfg.Regression.Fail = true
+	cfg.Retention.PerBranch = 5
+	cfg.TerminalWidth = 80
+	cfg.Skip = []string{"vendor/"}
+	cfg.PerFile.Statements["main.go"] = 10
+	cfg.PerPackage.Blocks["pkg/foo"] = 20
+	b, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal(b, &doc))
+
+	s := schema.Config()
+	for key := range doc {
+		require.Contains(t, s.Properties, key)
+	}
+	require.Len(t, s.Properties, len(doc))
+	require.Equal(t, false, s.AdditionalProperties)
+	require.InDelta(t, 100.0, *s.Properties["statementThreshold"].Maximum, 0)
+	require.Contains(t, s.Properties["format"].Enum, config.FormatJSON)
+	require.Empty(t, s.Required)
+}
+
+func TestResults_CoversJSONFields(t *testing.T) {
+	profiles := []*cover.Profile{
+		{
+			FileName: "foo/bar.go",
+			Blocks: []cover.ProfileBlock{
+				{NumStmt: 2, Count: 1},
+				{NumStmt: 2, Count: 0},
+			},
+		},
+	}
+	r, _ := compute.CollectResults(profiles, new(config.Config))
+	b, err := json.Marshal(r)
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	s := schema.Results()
+	require.ElementsMatch(t, []string{"byFile", "byPackage", "byTotal"}, s.Required)
+
+	byFile := s.Properties["byFile"].Items
+	for key := range doc["byFile"].([]any)[0].(map[string]any) {
+		require.Contains(t, byFile.Properties, key)
+	}
+	require.Contains(t, byFile.Required, "file")
+	require.Contains(t, byFile.Required, "statementsCovered")
+	require.NotContains(t, byFile.Required, "uncoveredLines")
+
+	byPackage := s.Properties["byPackage"].Items
+	for key := range doc["byPackage"].([]any)[0].(map[string]any) {
+		require.Contains(t, byPackage.Properties, key)
+	}
+
+	statements := s.Properties["byTotal"].Properties["statements"]
+	for key := range doc["byTotal"].(map[string]any)["statements"].(map[string]any) {
+		require.Contains(t, statements.Properties, key)
+	}
+}
+
+func TestHistory_TimestampIsDateTime(t *testing.T) {
+	s := schema.History()
+	entry := s.Properties["entries"].Items
+	require.Equal(t, "string", entry.Properties["timestamp"].Type)
+	require.Equal(t, "date-time", entry.Properties["timestamp"].Format)
+	require.Equal(t, "object", entry.Properties["results"].Type)
+	require.Empty(t, entry.Required)
+}
+
+func TestEnvelope_HasMetadataAndResults(t *testing.T) {
+	s := schema.Envelope()
+	require.ElementsMatch(t, []string{"schemaVersion", "metadata", "results"}, s.Required)
+	require.Contains(t, s.Properties["metadata"].Properties, "thresholds")
+
+	// the envelope carries the comparison next to the results rather than in them.
+	results := schema.Results()
+	require.Equal(t, results.Properties["comparison"], s.Properties["comparison"])
+	delete(results.Properties, "comparison")
+	require.Equal(t, results.Properties, s.Properties["results"].Properties)
+}
+
+func TestResults_ComparisonIsOptional(t *testing.T) {
+	s := schema.Results()
+	require.Contains(t, s.Properties, "comparison")
+	require.NotContains(t, s.Required, "comparison")
+	require.ElementsMatch(t, []string{"byFile", "byPackage", "byTotal"}, s.Required)
+
+	comparison := s.Properties["comparison"]
+	require.Contains(t, comparison.Properties["byTotal"].Properties, "statements")
+	require.NotContains(t, comparison.Properties["byTotal"].Required, "lines")
+}
@@ -0,0 +1,11 @@
+// Package schemas provides the published JSON Schemas for go-covercheck.
+// The files are generated with `go-covercheck schema <name>` (see the
+// Makefile "schemas" target) and tests keep them in sync with the Go types.
+package schemas
+
+import "embed"
+
+// Files contains the embedded *.schema.json files.
+//
+//go:embed *.schema.json
+var Files embed.FS